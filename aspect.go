@@ -0,0 +1,91 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// AspectLocked is a Node that keeps the node that it wraps at a fixed aspect ratio, e.g. so that a product shot would
+// always stay square. A split with an AspectLocked child sizes that child to match the aspect ratio instead of using
+// its own ratio, unless the size constraints of the split say otherwise or honouring the aspect ratio would leave no
+// room for the other child. The aspect ratios of both children are honoured as well as possible if both are locked.
+// Whenever the cell of the node still doesn't have the aspect ratio, the wrapped node is centered in the cell and the
+// rest of the cell is padded with the background. Only direct children of a split, possibly wrapped in Filtered or
+// Watermark nodes, are taken into account.
+type AspectLocked struct {
+	Node Node
+	// AspectRatio is the width of the node divided by its height, e.g. 1 for a square.
+	AspectRatio float32
+	// Background is the color of the padding. The padding is painted like the borders when the node is drawn with
+	// borders and left transparent otherwise if the Background isn't set.
+	Background color.Color
+}
+
+// WithAspectRatio wraps the picture in an AspectLocked node, see AspectLocked.
+func (n Picture) WithAspectRatio(aspectRatio float32) AspectLocked {
+	return AspectLocked{Node: n, AspectRatio: aspectRatio}
+}
+
+func (n AspectLocked) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n AspectLocked) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// nodeRect returns the rectangle that the wrapped node, including its border, is drawn into: the largest rectangle
+// with the aspect ratio that fits into the cell, centered in it
+func (n AspectLocked) nodeRect(width, height, borderWidth int) image.Rectangle {
+	full := image.Rect(0, 0, width, height)
+	contentWidth, contentHeight := width-2*borderWidth, height-2*borderWidth
+	if !validRatio(n.AspectRatio) || contentWidth <= 0 || contentHeight <= 0 {
+		return full
+	}
+	if float32(contentWidth) > float32(contentHeight)*n.AspectRatio {
+		contentWidth = int(float32(contentHeight)*n.AspectRatio + 0.5)
+	} else {
+		contentHeight = int(float32(contentWidth)/n.AspectRatio + 0.5)
+	}
+	nodeWidth, nodeHeight := contentWidth+2*borderWidth, contentHeight+2*borderWidth
+	min := image.Pt((width-nodeWidth)/2, (height-nodeHeight)/2)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(nodeWidth, nodeHeight))}
+}
+
+// paintPadding draws the background of the node into the given rectangle of dst, before the wrapped node is drawn
+// over it
+func (n AspectLocked) paintPadding(dst *image.RGBA, rect image.Rectangle, border image.Image, borderWidth int) {
+	if borderWidth > 0 {
+		draw.Draw(dst, rect, border, rect.Min, draw.Over)
+	}
+	if n.Background != nil {
+		draw.Draw(dst, n.backgroundRect(rect, borderWidth), image.NewUniform(n.Background), image.ZP, draw.Over)
+	}
+}
+
+// backgroundRect returns the part of the given rectangle that's painted with the Background, which is everything
+// within the border
+func (n AspectLocked) backgroundRect(rect image.Rectangle, borderWidth int) image.Rectangle {
+	return insetRect(rect, borderWidth).Intersect(rect)
+}
+
+// lockedAspectRatio returns the aspect ratio that the node is locked to, looking through the wrapper nodes that are
+// drawn into the same rectangle as the nodes that they wrap
+func lockedAspectRatio(n Node) (float32, bool) {
+	switch n := n.(type) {
+	case AspectLocked:
+		return n.AspectRatio, validRatio(n.AspectRatio)
+	case Filtered:
+		return lockedAspectRatio(n.Node)
+	case Watermark:
+		return lockedAspectRatio(n.Node)
+	}
+	return 0, false
+}
+
+// leavesRoom reports whether both children of a split have room for some content when the first child, including
+// its borders, gets the given part of the total
+func leavesRoom(total, first, borderWidth int) bool {
+	return first > 2*borderWidth && total-first > 2*borderWidth
+}
@@ -0,0 +1,96 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AspectLocked", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		green = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		blue  = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		gray  = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		a, b  Picture
+	)
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(100, 100, red)}
+		b = Picture{uniformImage(100, 100, green)}
+	})
+
+	It("makes the parent split honour the aspect ratio", func() {
+		n := VerticalSplit{Left: a.WithAspectRatio(1), Right: b, Ratio: 1}
+		Expect(placementRects(n, 400, 100, 0)).To(Equal([]image.Rectangle{
+			image.Rect(0, 0, 100, 100),
+			image.Rect(100, 0, 400, 100),
+		}))
+		h := HorizontalSplit{Top: a, Bottom: b.WithAspectRatio(2), Ratio: 1}
+		Expect(placementRects(h, 200, 400, 0)).To(Equal([]image.Rectangle{
+			image.Rect(0, 0, 200, 300),
+			image.Rect(0, 300, 200, 400),
+		}))
+	})
+
+	It("honours the aspect ratio with borders", func() {
+		n := VerticalSplit{Left: a.WithAspectRatio(1), Right: b, Ratio: 1}
+		Expect(placementRects(n, 400, 100, 5)).To(Equal([]image.Rectangle{
+			image.Rect(5, 5, 95, 95),
+			image.Rect(100, 5, 395, 95),
+		}))
+	})
+
+	It("honours the aspect ratios of both children", func() {
+		n := VerticalSplit{Left: a.WithAspectRatio(1), Right: b.WithAspectRatio(2), Ratio: 1}
+		Expect(placementRects(n, 300, 100, 0)).To(Equal([]image.Rectangle{
+			image.Rect(0, 0, 100, 100),
+			image.Rect(100, 0, 300, 100),
+		}))
+	})
+
+	It("looks through filters", func() {
+		n := VerticalSplit{Left: Filtered{Node: a.WithAspectRatio(1)}, Right: b, Ratio: 1}
+		Expect(placementRects(n, 400, 100, 0)[0]).To(Equal(image.Rect(0, 0, 100, 100)))
+	})
+
+	It("pads the node with the background if the aspect ratio can't be honoured", func() {
+		n := VerticalSplit{Left: AspectLocked{Node: a, AspectRatio: 1, Background: blue}, Right: b, Ratio: 1}
+		Expect(placementRects(n, 100, 200, 0)[0]).To(Equal(image.Rect(0, 75, 50, 125)))
+		i := n.Draw(100, 200)
+		Expect(i.At(25, 10)).To(Equal(blue))
+		Expect(i.At(25, 74)).To(Equal(blue))
+		Expect(i.At(25, 75)).To(Equal(red))
+		Expect(i.At(25, 124)).To(Equal(red))
+		Expect(i.At(25, 125)).To(Equal(blue))
+		Expect(i.At(75, 10)).To(Equal(green))
+	})
+
+	It("pads the node like the borders without a background", func() {
+		n := VerticalSplit{Left: a.WithAspectRatio(1), Right: b, Ratio: 1}
+		Expect(placementRects(n, 100, 200, 5)[0]).To(Equal(image.Rect(5, 78, 48, 121)))
+		i := n.DrawWithBorder(100, 200, gray, 5)
+		Expect(i.At(25, 20)).To(Equal(gray))
+		Expect(i.At(25, 77)).To(Equal(gray))
+		Expect(i.At(25, 78)).To(Equal(red))
+	})
+
+	It("lets size constraints win over the aspect ratio", func() {
+		n := VerticalSplit{Left: a.WithAspectRatio(1), Right: b, Ratio: 1, LeftSize: FixedSize(150)}
+		Expect(placementRects(n, 400, 100, 0)[0]).To(Equal(image.Rect(25, 0, 125, 100)))
+	})
+
+	It("is validated and normalized", func() {
+		Expect(Validate(AspectLocked{Node: a})).To(MatchError("invalid tree: root: invalid aspect ratio 0"))
+		Expect(Normalize(AspectLocked{AspectRatio: 1})).To(BeNil())
+	})
+
+	It("is included in the tree dump", func() {
+		Expect(DumpTree(a.WithAspectRatio(1), 200, 100, 0)).To(Equal("AspectLocked ratio=1 rect=(0,0)-(200,100)\n" +
+			"  Picture #0 100x100 rect=(50,0)-(150,100)\n"))
+	})
+})
@@ -0,0 +1,101 @@
+package picasso
+
+import (
+	"container/list"
+	"image"
+	"sync"
+)
+
+// ResizeKey identifies a picture that has been resized to fill an area of a certain size.
+type ResizeKey struct {
+	// Source is the image of the Picture. Images are compared by identity, so the same decoded image or Source has
+	// to be used for the cache to be hit.
+	Source image.Image
+	// Crop is the part of the source that is visible after resizing.
+	Crop image.Rectangle
+	// Width and Height are the dimensions of the resized picture.
+	Width  int
+	Height int
+	// Filter names the method used for resizing, e.g. the Quality of a Renderer.
+	Filter string
+}
+
+// ResizeCache stores resized pictures, so that drawing the same pictures repeatedly at the same sizes, e.g. in
+// different layouts or at different overall sizes, wouldn't have to redo the expensive resizing. Implementations
+// must be safe for concurrent use. The cached images must not be modified by either side.
+type ResizeCache interface {
+	Get(key ResizeKey) (image.Image, bool)
+	Put(key ResizeKey, m image.Image)
+}
+
+// NewLRUCache creates an in-memory ResizeCache that holds up to maxBytes bytes worth of pixels and evicts the least
+// recently used pictures first.
+func NewLRUCache(maxBytes int64) ResizeCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		entries:  make(map[ResizeKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+type lruCache struct {
+	mutex    sync.Mutex
+	maxBytes int64
+	bytes    int64
+	entries  map[ResizeKey]*list.Element
+	// order has the most recently used entries at the front
+	order *list.List
+}
+
+type lruEntry struct {
+	key   ResizeKey
+	image image.Image
+	bytes int64
+}
+
+func (c *lruCache) Get(key ResizeKey) (image.Image, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).image, true
+}
+
+func (c *lruCache) Put(key ResizeKey, m image.Image) {
+	size := imageBytes(m)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key, m, size})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lruCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*lruEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.bytes
+}
+
+// imageBytes estimates the memory used by the pixels of the image, assuming 4 bytes per pixel for any images with
+// unknown layouts
+func imageBytes(m image.Image) int64 {
+	switch m := m.(type) {
+	case *image.RGBA:
+		return int64(len(m.Pix))
+	case *image.NRGBA:
+		return int64(len(m.Pix))
+	}
+	return int64(4 * m.Bounds().Dx() * m.Bounds().Dy())
+}
@@ -0,0 +1,138 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type countingCache struct {
+	ResizeCache
+	mutex      sync.Mutex
+	hits, puts int
+}
+
+func (c *countingCache) Get(key ResizeKey) (image.Image, bool) {
+	m, ok := c.ResizeCache.Get(key)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if ok {
+		c.hits++
+	}
+	return m, ok
+}
+
+func (c *countingCache) Put(key ResizeKey, m image.Image) {
+	c.ResizeCache.Put(key, m)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.puts++
+}
+
+var _ = Describe("LRU cache", func() {
+	var (
+		cache  ResizeCache
+		source image.Image
+		key    = func(width int) ResizeKey {
+			return ResizeKey{Source: source, Width: width, Height: 10, Filter: "final"}
+		}
+	)
+
+	BeforeEach(func() {
+		// 3 pictures of 10x10 RGBA pixels
+		cache = NewLRUCache(3 * 10 * 10 * 4)
+		source = uniformImage(100, 100, color.White)
+	})
+
+	It("returns what was put in", func() {
+		m := uniformImage(10, 10, color.Black)
+		cache.Put(key(10), m)
+		cached, ok := cache.Get(key(10))
+		Expect(ok).To(BeTrue())
+		Expect(cached).To(BeIdenticalTo(m))
+
+		_, ok = cache.Get(key(11))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("evicts the least recently used pictures", func() {
+		cache.Put(key(1), uniformImage(10, 10, color.Black))
+		cache.Put(key(2), uniformImage(10, 10, color.Black))
+		cache.Put(key(3), uniformImage(10, 10, color.Black))
+		cache.Get(key(1))
+		cache.Put(key(4), uniformImage(10, 10, color.Black))
+
+		_, ok := cache.Get(key(2))
+		Expect(ok).To(BeFalse())
+		for _, width := range []int{1, 3, 4} {
+			_, ok := cache.Get(key(width))
+			Expect(ok).To(BeTrue())
+		}
+	})
+
+	It("doesn't store pictures larger than the whole cache", func() {
+		cache.Put(key(1), uniformImage(10, 10, color.Black))
+		cache.Put(key(2), uniformImage(100, 100, color.Black))
+		_, ok := cache.Get(key(1))
+		Expect(ok).To(BeTrue())
+		_, ok = cache.Get(key(2))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("is safe for concurrent use", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				for j := 0; j < 100; j++ {
+					cache.Put(key(i*100+j%5), uniformImage(10, 10, color.Black))
+					cache.Get(key(i*100 + j%7))
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	Describe("with a Renderer", func() {
+		var (
+			counting *countingCache
+			node     Node
+		)
+
+		BeforeEach(func() {
+			counting = &countingCache{ResizeCache: NewLRUCache(1 << 20)}
+			node = GoldenSpiralLayout().Compose([]image.Image{
+				uniformImage(30, 20, color.RGBA{0xff, 0x00, 0x00, 0xff}),
+				uniformImage(20, 30, color.RGBA{0x00, 0xff, 0x00, 0xff}),
+				uniformImage(20, 20, color.RGBA{0x00, 0x00, 0xff, 0xff}),
+			})
+		})
+
+		It("reuses resized pictures", func() {
+			r := Renderer{Cache: counting}
+			first := r.Draw(node, 100, 100)
+			Expect(counting.puts).To(Equal(3))
+			Expect(counting.hits).To(Equal(0))
+
+			second := r.Draw(node, 100, 100)
+			Expect(counting.puts).To(Equal(3))
+			Expect(counting.hits).To(Equal(3))
+			Expect(second).To(Equal(first))
+		})
+
+		It("distinguishes sizes", func() {
+			r := Renderer{Cache: counting}
+			r.Draw(node, 100, 100)
+			r.DrawWithBorder(node, 100, 100, color.White, 2)
+			Expect(counting.puts).To(Equal(6))
+			Expect(counting.hits).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/disintegration/gift"
+)
+
+// Canvas is a Node that places its items freely instead of tiling them, so that they can overlap, be rotated and be
+// framed like instant photos, e.g. for a collage of photos scattered across a table. Items with a higher Z are drawn
+// on top of those with a lower one and items with the same Z are drawn in order. The parts of items that are outside
+// of the canvas are cut off.
+//
+// The canvas is painted with its Background first. Without a Background it's painted like the borders when drawn with
+// borders and left transparent otherwise. The nodes of the items are drawn with the same borders as any other nodes.
+// Placements and the outputs that are based on them, such as WriteSVG, ignore the rotations and the frames of the
+// items.
+type Canvas struct {
+	Items      []CanvasItem
+	Background color.Color
+}
+
+// CanvasItem is a node placed on a Canvas. The position and the size of the item are relative to the size of the
+// canvas, so that the same canvas could be drawn at any size, and they include the frame of the item.
+type CanvasItem struct {
+	Node Node
+	// X and Y are the center of the item, from 0 for the left or the top edge of the canvas to 1 for the right or the
+	// bottom edge.
+	X float64
+	Y float64
+	// Width and Height are the size of the item, with 1 being the width or the height of the canvas.
+	Width  float64
+	Height float64
+	// Z determines the order in which the items are drawn.
+	Z int
+	// Rotation is the angle in degrees that the item is rotated by clockwise around its center.
+	Rotation float64
+	// Frame, if set, is drawn around the node of the item.
+	Frame *PolaroidFrame
+}
+
+// PolaroidFrame is a frame that's wider at the bottom than at the top and the sides, like that of an instant photo.
+type PolaroidFrame struct {
+	// Color of the frame. Defaults to white.
+	Color color.Color
+	// Margin is the width of the frame at the top and the sides relative to the width of the item. Defaults to 0.05.
+	Margin float64
+	// Bottom is the width of the frame at the bottom relative to the width of the item. Defaults to 0.2.
+	Bottom float64
+}
+
+func (n Canvas) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n Canvas) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// drawOrder returns the indexes of the items in the order that they're drawn in
+func (n Canvas) drawOrder() []int {
+	order := make([]int, len(n.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return n.Items[order[i]].Z < n.Items[order[j]].Z
+	})
+	return order
+}
+
+// paintBackground paints the whole canvas before any of the items are drawn
+func (n Canvas) paintBackground(dst *image.RGBA, rect image.Rectangle, border image.Image, borderWidth int) {
+	if n.Background != nil {
+		draw.Draw(dst, rect, image.NewUniform(n.Background), image.ZP, draw.Over)
+	} else if borderWidth > 0 {
+		draw.Draw(dst, rect, border, rect.Min, draw.Over)
+	}
+}
+
+// itemRect returns the rectangle of the item, including its frame, on a canvas of the given size, before it's rotated
+func (item CanvasItem) itemRect(width, height int) image.Rectangle {
+	itemWidth := int(item.Width*float64(width) + 0.5)
+	itemHeight := int(item.Height*float64(height) + 0.5)
+	if itemWidth <= 0 || itemHeight <= 0 {
+		return image.Rectangle{}
+	}
+	min := image.Pt(
+		int(item.X*float64(width)+0.5)-itemWidth/2,
+		int(item.Y*float64(height)+0.5)-itemHeight/2,
+	)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(itemWidth, itemHeight))}
+}
+
+// nodeRect returns the rectangle within the frame that the node of the item is drawn into, relative to the item
+func (item CanvasItem) nodeRect(width, height int) image.Rectangle {
+	full := image.Rect(0, 0, width, height)
+	if item.Frame == nil {
+		return full
+	}
+	margin, bottom := item.Frame.Margin, item.Frame.Bottom
+	if margin <= 0 {
+		margin = 0.05
+	}
+	if bottom <= 0 {
+		bottom = 0.2
+	}
+	sides := int(margin*float64(width) + 0.5)
+	r := image.Rect(sides, sides, width-sides, height-int(bottom*float64(width)+0.5))
+	if r.Min.X >= r.Max.X || r.Min.Y >= r.Max.Y {
+		return image.Rectangle{}
+	}
+	return r
+}
+
+func (item CanvasItem) frameColor() color.Color {
+	if item.Frame.Color == nil {
+		return color.White
+	}
+	return item.Frame.Color
+}
+
+// paintCanvasItem draws the item with its frame into the canvas in the given rectangle of dst, rotating it if needed
+func (r Renderer) paintCanvasItem(dst *image.RGBA, item CanvasItem, canvas image.Rectangle, borderColor color.Color, borderWidth int) {
+	itemRect := item.itemRect(canvas.Dx(), canvas.Dy()).Add(canvas.Min)
+	if itemRect.Empty() {
+		return
+	}
+	// The item is drawn in the coordinates of the whole canvas, so that border fills would be continuous
+	m := image.NewRGBA(itemRect)
+	if item.Frame != nil {
+		draw.Draw(m, itemRect, image.NewUniform(item.frameColor()), image.ZP, draw.Src)
+	}
+	nodeRect := item.nodeRect(itemRect.Dx(), itemRect.Dy())
+	if item.Node != nil && !nodeRect.Empty() {
+		r.paint(m, item.Node, nodeRect.Add(itemRect.Min), borderColor, borderWidth)
+	}
+
+	if math.Mod(item.Rotation, 360) == 0 {
+		clipped := itemRect.Intersect(canvas)
+		draw.Draw(dst, clipped, m, clipped.Min, draw.Over)
+		return
+	}
+	// gift rotates counter-clockwise
+	g := gift.New(gift.Rotate(float32(-item.Rotation), color.Transparent, r.Quality.interpolation()))
+	rotated := image.NewRGBA(g.Bounds(m.Bounds()))
+	g.Draw(rotated, m)
+	// The rotated item is larger than the item, but it has the same center
+	size := rotated.Bounds().Size()
+	min := itemRect.Min.Add(itemRect.Size().Div(2)).Sub(size.Div(2))
+	clipped := image.Rectangle{Min: min, Max: min.Add(size)}.Intersect(canvas)
+	draw.Draw(dst, clipped, rotated, rotated.Bounds().Min.Add(clipped.Min.Sub(min)), draw.Over)
+}
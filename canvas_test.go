@@ -0,0 +1,123 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Canvas", func() {
+	var (
+		red    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		green  = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		blue   = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		gray   = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		white  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		a, b   Picture
+		canvas Canvas
+	)
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(100, 100, red)}
+		b = Picture{uniformImage(100, 100, green)}
+		canvas = Canvas{
+			Background: blue,
+			Items: []CanvasItem{
+				{Node: a, X: 0.4, Y: 0.5, Width: 0.5, Height: 0.5, Z: 1},
+				{Node: b, X: 0.6, Y: 0.5, Width: 0.5, Height: 0.5},
+			},
+		}
+	})
+
+	It("places the items relative to the size of the canvas in the order they're drawn in", func() {
+		Expect(placementRects(canvas, 200, 200, 0)).To(Equal([]image.Rectangle{
+			image.Rect(70, 50, 170, 150),
+			image.Rect(30, 50, 130, 150),
+		}))
+		Expect(placementRects(canvas, 400, 200, 0)[1]).To(Equal(image.Rect(60, 50, 260, 150)))
+	})
+
+	It("draws the items with higher Z values on top", func() {
+		i := canvas.Draw(200, 200)
+		Expect(i.At(10, 10)).To(Equal(blue))
+		Expect(i.At(50, 100)).To(Equal(red))
+		Expect(i.At(100, 100)).To(Equal(red))
+		Expect(i.At(150, 100)).To(Equal(green))
+	})
+
+	It("paints the canvas like the borders without a background", func() {
+		canvas.Background = nil
+		Expect(canvas.DrawWithBorder(200, 200, gray, 2).At(10, 10)).To(Equal(gray))
+		Expect(canvas.Draw(200, 200).At(10, 10)).To(Equal(color.RGBA{}))
+	})
+
+	It("draws polaroid frames around the items", func() {
+		n := Canvas{Items: []CanvasItem{{Node: a, X: 0.5, Y: 0.5, Width: 1, Height: 1, Frame: &PolaroidFrame{}}}}
+		Expect(placementRects(n, 100, 140, 0)).To(Equal([]image.Rectangle{image.Rect(5, 5, 95, 120)}))
+		i := n.Draw(100, 140)
+		Expect(i.At(2, 2)).To(Equal(white))
+		Expect(i.At(50, 50)).To(Equal(red))
+		Expect(i.At(50, 130)).To(Equal(white))
+	})
+
+	It("rotates the items around their centers", func() {
+		n := Canvas{Background: blue, Items: []CanvasItem{{Node: a, X: 0.5, Y: 0.5, Width: 0.5, Height: 0.25, Rotation: 90}}}
+		i := n.Draw(200, 200)
+		Expect(i.At(100, 60)).To(Equal(red))
+		Expect(i.At(100, 140)).To(Equal(red))
+		Expect(i.At(60, 100)).To(Equal(blue))
+		Expect(i.At(140, 100)).To(Equal(blue))
+	})
+
+	It("cuts off the parts of the items that are outside of the canvas", func() {
+		n := VerticalSplit{
+			Ratio: 1,
+			Left:  Canvas{Items: []CanvasItem{{Node: a, X: 1, Y: 0.5, Width: 1, Height: 1, Rotation: 30}}},
+			Right: b,
+		}
+		i := n.Draw(200, 100)
+		Expect(i.At(99, 50)).To(Equal(red))
+		Expect(i.At(100, 50)).To(Equal(green))
+		Expect(i.At(150, 50)).To(Equal(green))
+	})
+
+	It("is transformed along with the rest of the tree", func() {
+		canvas.Items[0].Rotation = 10
+		mirrored := MirrorHorizontally(canvas).(Canvas)
+		Expect(mirrored.Items[0]).To(Equal(CanvasItem{Node: a, X: 0.6, Y: 0.5, Width: 0.5, Height: 0.5, Z: 1, Rotation: -10}))
+		Expect(mirrored.Background).To(Equal(blue))
+		rotated := RotateClockwise(Canvas{Items: []CanvasItem{{Node: a, X: 0.25, Y: 0.25, Width: 0.5, Height: 0.25}}}).(Canvas)
+		Expect(rotated.Items[0]).To(Equal(CanvasItem{Node: a, X: 0.75, Y: 0.25, Width: 0.25, Height: 0.5}))
+		Expect(canvas.Items[0].X).To(Equal(0.4))
+	})
+
+	It("maps the nodes of the items in the order they're drawn in", func() {
+		var nodes []Node
+		Map(canvas, func(n Node) Node {
+			if _, ok := n.(Picture); ok {
+				nodes = append(nodes, n)
+				return Filtered{Node: n}
+			}
+			return n
+		})
+		Expect(nodes).To(Equal([]Node{b, a}))
+		Expect(Map(canvas, func(n Node) Node { return n })).To(Equal(canvas))
+	})
+
+	It("is validated and normalized", func() {
+		n := Canvas{Items: []CanvasItem{{Node: a, Width: 1}, {Node: nil, Width: 1, Height: 1}}}
+		Expect(Validate(n)).To(MatchError("invalid tree: root: invalid size of Items[0] 1x0; Items[1].Node: missing node"))
+		Expect(Normalize(n)).To(Equal(Canvas{Items: []CanvasItem{{Node: a, Width: 1}}}))
+		Expect(Normalize(Canvas{Items: []CanvasItem{{}}})).To(BeNil())
+	})
+
+	It("is included in the tree dump", func() {
+		Expect(DumpTree(canvas, 200, 200, 0)).To(Equal("Canvas items=2 rect=(0,0)-(200,200)\n" +
+			"  Picture #0 100x100 rect=(70,50)-(170,150)\n" +
+			"  Picture #1 100x100 rect=(30,50)-(130,150)\n"))
+	})
+})
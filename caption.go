@@ -0,0 +1,216 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font is a TrueType or OpenType font that captions can be drawn with.
+type Font struct {
+	font *opentype.Font
+}
+
+// ParseFont parses a TrueType or OpenType font, e.g. the contents of a .ttf or an .otf file.
+func ParseFont(data []byte) (*Font, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Font{f}, nil
+}
+
+// FontFile parses the TrueType or OpenType font in the file at the given path, see ParseFont.
+func FontFile(path string) (*Font, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFont(data)
+}
+
+// Alignment determines where content that is narrower than the area it's drawn into is placed horizontally.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+)
+
+// CaptionPosition determines where a caption is drawn relative to the node it describes.
+type CaptionPosition int
+
+const (
+	// CaptionOverlay draws the caption on top of the bottom part of the node.
+	CaptionOverlay CaptionPosition = iota
+	// CaptionBelow draws the caption beneath the node, which makes the node itself shorter.
+	CaptionBelow
+)
+
+// CaptionStyle describes how the lines of a caption are drawn.
+type CaptionStyle struct {
+	// Font is the font of the text. Captions without a font are not drawn.
+	Font *Font
+	// Size is the size of the font in pixels. Defaults to 12.
+	Size float64
+	// Color is the color of the text. Defaults to black.
+	Color color.Color
+	// Background, if set, is the color of the band that the text is drawn on. Translucent colors keep the node
+	// visible under a CaptionOverlay.
+	Background color.Color
+	Align      Alignment
+	Position   CaptionPosition
+	// Padding is the space in pixels between the text and the edges of the band.
+	Padding int
+}
+
+// Captioned is a Node that draws a caption, e.g. a title and a credit line, on or beneath the node that it wraps.
+// Every line is drawn on a line of its own and lines that don't fit the width of the cell are truncated with an
+// ellipsis. Captions are only drawn into images, vector outputs such as WriteSVG and WritePDF only include the
+// pictures of the wrapped node.
+type Captioned struct {
+	Node  Node
+	Lines []string
+	Style CaptionStyle
+}
+
+func (n Captioned) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n Captioned) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// rects returns the rectangle that the wrapped node, including its border, is drawn into and the rectangle of the
+// band that the caption is drawn on.
+func (n Captioned) rects(width, height, borderWidth int) (image.Rectangle, image.Rectangle) {
+	full := image.Rect(0, 0, width, height)
+	inBorder := insetRect(full, borderWidth)
+	bandHeight := 0
+	if face, ok := n.Style.face(); ok {
+		bandHeight = len(n.Lines)*face.Metrics().Height.Ceil() + 2*n.Style.Padding
+		face.Close()
+	}
+	if len(n.Lines) == 0 || inBorder.Empty() {
+		bandHeight = 0
+	} else if bandHeight > inBorder.Dy() {
+		bandHeight = inBorder.Dy()
+	}
+
+	band := image.Rect(inBorder.Min.X, inBorder.Max.Y-bandHeight, inBorder.Max.X, inBorder.Max.Y)
+	if n.Style.Position == CaptionBelow && bandHeight > 0 {
+		// The node keeps its full border and the band gets a border of its own on the sides and at the bottom
+		return image.Rect(0, 0, width, band.Min.Y), band
+	}
+	return full, band
+}
+
+// paintBandBorder draws the border around the sides and the bottom of a band that's beneath the node
+func (n Captioned) paintBandBorder(dst *image.RGBA, rect, band image.Rectangle, border image.Image) {
+	if n.Style.Position != CaptionBelow || band.Empty() {
+		return
+	}
+	for _, r := range []image.Rectangle{
+		image.Rect(rect.Min.X, band.Min.Y, band.Min.X, rect.Max.Y),
+		image.Rect(band.Max.X, band.Min.Y, rect.Max.X, rect.Max.Y),
+		image.Rect(band.Min.X, band.Max.Y, band.Max.X, rect.Max.Y),
+	} {
+		draw.Draw(dst, r, border, r.Min, draw.Over)
+	}
+}
+
+// paintCaption draws the band and the lines of the caption into the given band of dst
+func (n Captioned) paintCaption(dst *image.RGBA, band image.Rectangle) {
+	face, ok := n.Style.face()
+	if !ok || band.Empty() {
+		return
+	}
+	defer face.Close()
+
+	if n.Style.Background != nil {
+		draw.Draw(dst, band, image.NewUniform(n.Style.Background), image.ZP, draw.Over)
+	}
+	textColor := n.Style.Color
+	if textColor == nil {
+		textColor = color.Black
+	}
+	drawer := font.Drawer{
+		// Drawing into a sub-image clips any glyphs that would otherwise extend past the band
+		Dst:  dst.SubImage(band).(*image.RGBA),
+		Src:  image.NewUniform(textColor),
+		Face: face,
+	}
+	metrics := face.Metrics()
+	available := band.Dx() - 2*n.Style.Padding
+	for i, line := range n.Lines {
+		line = fitText(face, line, available)
+		x := band.Min.X + n.Style.Padding
+		switch n.Style.Align {
+		case AlignCenter:
+			x += (available - font.MeasureString(face, line).Ceil()) / 2
+		case AlignRight:
+			x += available - font.MeasureString(face, line).Ceil()
+		}
+		y := band.Min.Y + n.Style.Padding + i*metrics.Height.Ceil() + metrics.Ascent.Ceil()
+		drawer.Dot = fixed.P(x, y)
+		drawer.DrawString(line)
+	}
+}
+
+// Fit returns the text truncated with an ellipsis, so that it would fit the given width in pixels when drawn in this
+// style. The text is returned as is if it fits and an empty string is returned if not even the ellipsis fits.
+func (s CaptionStyle) Fit(text string, width int) string {
+	face, ok := s.face()
+	if !ok {
+		return text
+	}
+	defer face.Close()
+	return fitText(face, text, width)
+}
+
+func (s CaptionStyle) face() (font.Face, bool) {
+	if s.Font == nil {
+		return nil, false
+	}
+	size := s.Size
+	if size <= 0 {
+		size = 12
+	}
+	face, err := opentype.NewFace(s.Font.font, &opentype.FaceOptions{
+		Size: size,
+		// At 72 DPI a point is a pixel
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, false
+	}
+	return face, true
+}
+
+func fitText(face font.Face, text string, width int) string {
+	limit := fixed.I(width)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	ellipsis := "…"
+	if _, ok := face.GlyphAdvance('…'); !ok {
+		ellipsis = "..."
+	}
+	runes := []rune(text)
+	for i := len(runes) - 1; i >= 0; i-- {
+		truncated := strings.TrimRight(string(runes[:i]), " ") + ellipsis
+		if font.MeasureString(face, truncated) <= limit {
+			return truncated
+		}
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+	"golang.org/x/image/font/gofont/goregular"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Captioned", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		black = color.RGBA{0x00, 0x00, 0x00, 0xff}
+		gray  = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		font  *Font
+		style CaptionStyle
+	)
+
+	BeforeEach(func() {
+		var err error
+		font, err = ParseFont(goregular.TTF)
+		Expect(err).NotTo(HaveOccurred())
+		style = CaptionStyle{
+			Font:       font,
+			Size:       12,
+			Color:      black,
+			Background: white,
+			Padding:    4,
+		}
+	})
+
+	// columnsWith returns the x coordinates of the columns that have pixels of the given color in the given rectangle
+	columnsWith := func(m image.Image, r image.Rectangle, c color.Color) []int {
+		var columns []int
+		for x := r.Min.X; x < r.Max.X; x++ {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				if m.At(x, y) == c {
+					columns = append(columns, x)
+					break
+				}
+			}
+		}
+		return columns
+	}
+
+	It("fails to parse fonts from garbage", func() {
+		_, err := ParseFont([]byte("not a font"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Fit", func() {
+		It("keeps text that fits as is", func() {
+			Expect(style.Fit("Guernica", 200)).To(Equal("Guernica"))
+		})
+
+		It("truncates text that doesn't fit with an ellipsis", func() {
+			fitted := style.Fit("The Old Guitarist, 1903-1904", 80)
+			Expect(fitted).To(HaveSuffix("…"))
+			Expect(len(fitted)).To(BeNumerically("<", len("The Old Guitarist, 1903-1904")))
+			Expect("The Old Guitarist, 1903-1904").To(HavePrefix(fitted[:len(fitted)-len("…")]))
+		})
+
+		It("returns nothing if not even the ellipsis fits", func() {
+			Expect(style.Fit("Guernica", 1)).To(Equal(""))
+		})
+	})
+
+	It("draws the caption on top of the bottom of the picture", func() {
+		node := Captioned{
+			Node:  Picture{uniformImage(100, 100, red)},
+			Lines: []string{"Title", "Credit"},
+			Style: style,
+		}
+		m := node.Draw(100, 100)
+		Expect(m.At(50, 0)).To(Equal(red))
+		// The band is two lines tall with padding on both sides
+		Expect(m.At(0, 99)).To(Equal(white))
+		Expect(m.At(0, 70)).To(Equal(white))
+		Expect(m.At(0, 60)).To(Equal(red))
+		Expect(columnsWith(m, m.Bounds(), black)).NotTo(BeEmpty())
+		Expect(Placements(node, 100, 100, 0)[0].Rect).To(Equal(image.Rect(0, 0, 100, 100)))
+	})
+
+	It("draws the caption beneath the picture", func() {
+		style.Position = CaptionBelow
+		node := Captioned{
+			Node:  Picture{uniformImage(100, 100, red)},
+			Lines: []string{"Title"},
+			Style: style,
+		}
+		m := node.DrawWithBorder(100, 100, gray, 2)
+		rect := Placements(node, 100, 100, 2)[0].Rect
+		Expect(rect.Min).To(Equal(image.Pt(2, 2)))
+		Expect(rect.Max.X).To(Equal(98))
+		Expect(rect.Max.Y).To(BeNumerically("<", 80))
+		// The border between the picture and the caption
+		Expect(m.At(50, rect.Max.Y)).To(Equal(gray))
+		Expect(m.At(50, rect.Max.Y+1)).To(Equal(gray))
+		Expect(m.At(2, rect.Max.Y+2)).To(Equal(white))
+		// The border around the caption
+		Expect(m.At(1, 97)).To(Equal(gray))
+		Expect(m.At(98, 97)).To(Equal(gray))
+		Expect(m.At(50, 98)).To(Equal(gray))
+		Expect(m.At(97, 97)).To(Equal(white))
+	})
+
+	It("blends translucent bands with the picture", func() {
+		style.Background = color.RGBA{0x00, 0x00, 0x00, 0x80}
+		node := Captioned{
+			Node:  Picture{uniformImage(100, 100, white)},
+			Lines: []string{"Title"},
+			Style: style,
+		}
+		Expect(node.Draw(100, 100).At(0, 99)).To(Equal(color.RGBA{0x7f, 0x7f, 0x7f, 0xff}))
+	})
+
+	expectAlignment := func(align Alignment, expectLeft, expectRight bool) {
+		style.Align = align
+		node := Captioned{
+			Node:  Picture{uniformImage(200, 100, red)},
+			Lines: []string{"Guernica"},
+			Style: style,
+		}
+		m := node.Draw(200, 100)
+		columns := columnsWith(m, image.Rect(0, 70, 200, 100), black)
+		Expect(columns).NotTo(BeEmpty())
+		Expect(columns[0] < 50).To(Equal(expectLeft))
+		Expect(columns[len(columns)-1] >= 150).To(Equal(expectRight))
+	}
+
+	It("aligns the text to the left", func() {
+		expectAlignment(AlignLeft, true, false)
+	})
+
+	It("aligns the text to the center", func() {
+		expectAlignment(AlignCenter, false, false)
+	})
+
+	It("aligns the text to the right", func() {
+		expectAlignment(AlignRight, false, true)
+	})
+
+	It("keeps truncated text within the padding of the cell", func() {
+		node := Captioned{
+			Node:  Picture{uniformImage(100, 100, red)},
+			Lines: []string{"Les Femmes d'Alger, Version O, 1955"},
+			Style: style,
+		}
+		m := node.Draw(100, 100)
+		columns := columnsWith(m, image.Rect(0, 70, 100, 100), black)
+		Expect(columns).NotTo(BeEmpty())
+		Expect(columns[0]).To(BeNumerically(">=", 4))
+		Expect(columns[len(columns)-1]).To(BeNumerically("<", 96))
+	})
+
+	It("draws just the node without a font", func() {
+		style.Font = nil
+		picture := Picture{uniformImage(100, 100, red)}
+		node := Captioned{
+			Node:  picture,
+			Lines: []string{"Title"},
+			Style: style,
+		}
+		Expect(node.Draw(100, 100)).To(Equal(picture.Draw(100, 100)))
+	})
+
+	It("draws the pictures of the wrapped node with the settings of a renderer", func() {
+		node := Captioned{
+			Node:  TopHeavyLayout().Compose([]image.Image{uniformImage(100, 100, red), uniformImage(100, 50, gray)}),
+			Lines: []string{"Title"},
+			Style: style,
+		}
+		Expect(Renderer{Quality: DraftQuality}.Draw(node, 100, 150)).To(Equal(node.Draw(100, 150)))
+	})
+})
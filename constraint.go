@@ -0,0 +1,124 @@
+package picasso
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint limits the size of a child of a split in pixels, along the direction of the split: the width of the
+// children of a VerticalSplit and the height of the children of a HorizontalSplit. The sizes exclude the borders
+// drawn by DrawWithBorder, so that a child fixed to 80 pixels is 80 pixels wide or tall with or without borders. The
+// zero value doesn't constrain the size at all.
+type Constraint struct {
+	// Fixed, if set, is the exact size of the child. The ratio of the split and the constraints of the other child
+	// are ignored then.
+	Fixed int
+	// Min and Max, if set, are the smallest and the largest size that the ratio of the split can give the child.
+	Min int
+	Max int
+}
+
+// FixedSize constrains a child of a split to exactly the given number of pixels.
+func FixedSize(pixels int) Constraint {
+	return Constraint{Fixed: pixels}
+}
+
+func (c Constraint) isZero() bool {
+	return c == Constraint{}
+}
+
+func (c Constraint) String() string {
+	var parts []string
+	if c.Fixed != 0 {
+		parts = append(parts, fmt.Sprintf("fixed=%d", c.Fixed))
+	}
+	if c.Min != 0 {
+		parts = append(parts, fmt.Sprintf("min=%d", c.Min))
+	}
+	if c.Max != 0 {
+		parts = append(parts, fmt.Sprintf("max=%d", c.Max))
+	}
+	return strings.Join(parts, ",")
+}
+
+// overridesRatio reports whether the constraints of the children of a split leave the ratio of the split unused
+func overridesRatio(first, second Constraint) bool {
+	return first.Fixed > 0 || second.Fixed > 0
+}
+
+// valid reports whether the constraint can be satisfied on its own
+func (c Constraint) valid() bool {
+	return c.Fixed >= 0 && c.Min >= 0 && c.Max >= 0 && (c.Max == 0 || c.Min <= c.Max)
+}
+
+// solveSplit divides total pixels between the first and the second child of a split and returns the size of the first
+// child. Both the total and the returned size include the borders of the children. The first child gets the desired
+// size, which comes from the ratio of the split, unless the constraints of the children call for something else. When
+// the constraints of the children conflict, the minimum sizes win over the maximum sizes and the minimum size of the
+// first child wins over that of the second child. If the maximum sizes of both children add up to less than the
+// total, the first child gets the rest.
+//
+// The children share the border between them, so the size is kept between borderWidth and total-borderWidth, which
+// keeps both children within the split even when one of them is squeezed down to nothing but its borders.
+func solveSplit(total, desired int, first, second Constraint, borderWidth int) int {
+	return clamp(constrainSplit(total, desired, first, second, borderWidth), borderWidth, total-borderWidth)
+}
+
+// constrainSplit returns the size of the first child that the constraints of the children call for, see solveSplit
+func constrainSplit(total, desired int, first, second Constraint, borderWidth int) int {
+	if first.isZero() && second.isZero() {
+		return desired
+	}
+	// The constraints exclude the borders on both sides of the child
+	borders := 2 * borderWidth
+
+	switch {
+	case first.Fixed > 0:
+		return first.Fixed + borders
+	case second.Fixed > 0:
+		return total - (second.Fixed + borders)
+	}
+
+	// The first child is limited by its own constraints and by the constraints of the second child, which gets
+	// whatever the first one doesn't take
+	lowest, highest := 0, total
+	if second.Max > 0 {
+		lowest = total - (second.Max + borders)
+	}
+	if first.Max > 0 {
+		highest = minInt(highest, first.Max+borders)
+	}
+	if second.Min > 0 {
+		highest = minInt(highest, total-(second.Min+borders))
+	}
+	if first.Min > 0 {
+		lowest = maxInt(lowest, first.Min+borders)
+	}
+	size := desired
+	if size > highest {
+		size = highest
+	}
+	// Checked last, so that the minimum sizes would win over the maximum sizes
+	if size < lowest {
+		size = lowest
+	}
+	return size
+}
+
+func clamp(v, lowest, highest int) int {
+	return maxInt(lowest, minInt(v, highest))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Constraint", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		green = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		gray  = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		left  Picture
+		right Picture
+	)
+
+	BeforeEach(func() {
+		left = Picture{uniformImage(100, 100, red)}
+		right = Picture{uniformImage(100, 100, green)}
+	})
+
+	widths := func(n Node, width, height, borderWidth int) []int {
+		var widths []int
+		for _, p := range Placements(n, width, height, borderWidth) {
+			widths = append(widths, p.Rect.Dx())
+		}
+		return widths
+	}
+
+	heights := func(n Node, width, height, borderWidth int) []int {
+		var heights []int
+		for _, p := range Placements(n, width, height, borderWidth) {
+			heights = append(heights, p.Rect.Dy())
+		}
+		return heights
+	}
+
+	table.DescribeTable("keeps fixed children at their size regardless of the canvas size",
+		func(width, borderWidth int) {
+			n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: FixedSize(80)}
+			Expect(widths(n, width, 100, borderWidth)[0]).To(Equal(80))
+		},
+		table.Entry("without borders on a small canvas", 200, 0),
+		table.Entry("without borders on a large canvas", 1000, 0),
+		table.Entry("with borders on a small canvas", 200, 5),
+		table.Entry("with borders on a large canvas", 1000, 5),
+	)
+
+	It("gives the rest of the canvas to the other child", func() {
+		n := HorizontalSplit{Top: left, Bottom: right, Ratio: 1, BottomSize: FixedSize(80)}
+		Expect(heights(n, 100, 500, 0)).To(Equal([]int{420, 80}))
+		Expect(heights(n, 100, 500, 5)).To(Equal([]int{405, 80}))
+	})
+
+	It("draws fixed children with borders where Placements puts them", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, RightSize: FixedSize(80)}
+		i, placements := DrawWithBorderAndPlacements(n, 400, 100, gray, 5)
+		Expect(placements[1].Rect).To(Equal(image.Rect(315, 5, 395, 95)))
+		Expect(i.At(314, 50)).To(Equal(gray))
+		Expect(i.At(315, 50)).To(Equal(green))
+		Expect(i.At(394, 50)).To(Equal(green))
+		Expect(i.At(395, 50)).To(Equal(gray))
+	})
+
+	It("only limits the ratio with minimum and maximum sizes", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Min: 100, Max: 300}}
+		Expect(widths(n, 150, 100, 0)).To(Equal([]int{100, 50}))
+		Expect(widths(n, 400, 100, 0)).To(Equal([]int{200, 200}))
+		Expect(widths(n, 1000, 100, 0)).To(Equal([]int{300, 700}))
+	})
+
+	It("applies the minimum and maximum sizes of the second child", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, RightSize: Constraint{Min: 100, Max: 300}}
+		Expect(widths(n, 150, 100, 0)).To(Equal([]int{50, 100}))
+		Expect(widths(n, 1000, 100, 0)).To(Equal([]int{700, 300}))
+	})
+
+	It("excludes the borders from minimum sizes", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Min: 100}}
+		Expect(widths(n, 150, 100, 5)).To(Equal([]int{100, 35}))
+	})
+
+	It("satisfies the constraints of both children if possible", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Min: 200}, RightSize: Constraint{Max: 50}}
+		Expect(widths(n, 300, 100, 0)).To(Equal([]int{250, 50}))
+	})
+
+	It("prefers the minimum size of the first child when the constraints conflict", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Min: 200}, RightSize: Constraint{Min: 200}}
+		Expect(widths(n, 300, 100, 0)).To(Equal([]int{200, 100}))
+		n = VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Max: 100}, RightSize: Constraint{Min: 250}}
+		Expect(widths(n, 300, 100, 0)).To(Equal([]int{50, 250}))
+		n = VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: Constraint{Max: 100}, RightSize: Constraint{Max: 100}}
+		Expect(widths(n, 300, 100, 0)).To(Equal([]int{200, 100}))
+	})
+
+	It("never gives a child more than the whole canvas", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: FixedSize(500)}
+		Expect(widths(n, 300, 100, 0)).To(Equal([]int{300, 0}))
+	})
+
+	It("keeps both children within the split when one of them gets the whole canvas", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, RightSize: FixedSize(300)}
+		Expect(placementRects(n, 300, 100, 5)[1]).To(Equal(image.Rect(5, 5, 295, 95)))
+		n = VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: FixedSize(300)}
+		Expect(placementRects(n, 300, 100, 5)[0]).To(Equal(image.Rect(5, 5, 295, 95)))
+		h := HorizontalSplit{Top: left, Bottom: right, Ratio: 1, TopSize: Constraint{Max: 1}, BottomSize: Constraint{Min: 100}}
+		Expect(placementRects(h, 100, 100, 5)[1]).To(Equal(image.Rect(5, 5, 95, 95)))
+		i := h.DrawWithBorder(100, 100, gray, 5)
+		Expect(i.At(50, 50)).To(Equal(green))
+	})
+
+	It("doesn't change splits without constraints", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 0.5}
+		Expect(widths(n, 400, 100, 0)).To(Equal([]int{134, 266}))
+		Expect(widths(n, 400, 100, 3)).To(Equal([]int{129, 262}))
+	})
+
+	It("keeps the constraints with their children when transforming trees", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: FixedSize(80)}
+		Expect(MirrorHorizontally(n)).To(Equal(VerticalSplit{Left: right, Right: left, Ratio: 1, RightSize: FixedSize(80)}))
+		Expect(RotateClockwise(n)).To(Equal(HorizontalSplit{Top: left, Bottom: right, Ratio: 1, TopSize: FixedSize(80)}))
+		Expect(RotateCounterClockwise(n)).To(Equal(HorizontalSplit{Top: right, Bottom: left, Ratio: 1, BottomSize: FixedSize(80)}))
+	})
+
+	It("is reported by Validate if it can't be satisfied", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, RightSize: Constraint{Min: 300, Max: 200}}
+		Expect(Validate(n)).To(MatchError("invalid tree: root: invalid RightSize min=300,max=200"))
+	})
+
+	It("makes the ratio optional when a child has a fixed size", func() {
+		Expect(Validate(VerticalSplit{Left: left, Right: right, LeftSize: FixedSize(80)})).To(Succeed())
+		Expect(Validate(HorizontalSplit{Top: left, Bottom: right, BottomSize: FixedSize(80)})).To(Succeed())
+		Expect(widths(VerticalSplit{Left: left, Right: right, RightSize: FixedSize(80)}, 200, 100, 0)).To(Equal([]int{120, 80}))
+		n := VerticalSplit{Left: left, Right: right, LeftSize: Constraint{Max: 80}}
+		Expect(Validate(n)).To(MatchError("invalid tree: root: invalid ratio 0"))
+	})
+
+	It("is included in the tree dump", func() {
+		n := VerticalSplit{Left: left, Right: right, Ratio: 1, LeftSize: FixedSize(80)}
+		Expect(DumpTree(n, 200, 100, 0)).To(Equal("VerticalSplit ratio=1 left=fixed=80 rect=(0,0)-(200,100)\n" +
+			"  Picture #0 100x100 rect=(0,0)-(80,100)\n" +
+			"  Picture #1 100x100 rect=(80,0)-(200,100)\n"))
+	})
+})
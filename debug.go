@@ -0,0 +1,180 @@
+package picasso
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func (n Picture) String() string {
+	if n.Picture == nil {
+		return "Picture(nil)"
+	}
+	return fmt.Sprintf("Picture(%dx%d)", n.Picture.Bounds().Dx(), n.Picture.Bounds().Dy())
+}
+
+func (n VerticalSplit) String() string {
+	return fmt.Sprintf("VerticalSplit(%g, %v, %v)", n.Ratio, n.Left, n.Right)
+}
+
+func (n HorizontalSplit) String() string {
+	return fmt.Sprintf("HorizontalSplit(%g, %v, %v)", n.Ratio, n.Top, n.Bottom)
+}
+
+func (n Captioned) String() string {
+	return fmt.Sprintf("Captioned(%q, %v)", n.Lines, n.Node)
+}
+
+func (n Filtered) String() string {
+	return fmt.Sprintf("Filtered(%d filter(s), %v)", len(n.Filters), n.Node)
+}
+
+func (n AspectLocked) String() string {
+	return fmt.Sprintf("AspectLocked(%g, %v)", n.AspectRatio, n.Node)
+}
+
+func (n Grid) String() string {
+	return fmt.Sprintf("Grid(%dx%d, %v)", len(n.Rows), len(n.Columns), Children(n))
+}
+
+func (n Canvas) String() string {
+	return fmt.Sprintf("Canvas(%v)", Children(n))
+}
+
+func (n Watermark) String() string {
+	return fmt.Sprintf("Watermark(%v)", n.Node)
+}
+
+// DumpTree formats the tree with one node per line, children indented under their parents, along with the
+// rectangles that the nodes are drawn into at the given size. See Walk for the meaning of the rectangles. Pictures
+// are numbered in the same order that Placements uses.
+func DumpTree(n Node, width, height, borderWidth int) string {
+	var b strings.Builder
+	pictures := 0
+	var dump func(n Node, rect image.Rectangle, depth int)
+	dump = func(n Node, rect image.Rectangle, depth int) {
+		b.WriteString(strings.Repeat("  ", depth))
+		switch n := n.(type) {
+		case nil:
+			b.WriteString("<nil>\n")
+			return
+		case Picture:
+			fmt.Fprintf(&b, "Picture #%d", pictures)
+			pictures++
+			if n.Picture != nil {
+				fmt.Fprintf(&b, " %dx%d", n.Picture.Bounds().Dx(), n.Picture.Bounds().Dy())
+			}
+		case VerticalSplit:
+			fmt.Fprintf(&b, "VerticalSplit ratio=%g", n.Ratio)
+			writeConstraint(&b, "left", n.LeftSize)
+			writeConstraint(&b, "right", n.RightSize)
+		case HorizontalSplit:
+			fmt.Fprintf(&b, "HorizontalSplit ratio=%g", n.Ratio)
+			writeConstraint(&b, "top", n.TopSize)
+			writeConstraint(&b, "bottom", n.BottomSize)
+		case Captioned:
+			fmt.Fprintf(&b, "Captioned %q", n.Lines)
+		case Filtered:
+			fmt.Fprintf(&b, "Filtered filters=%d", len(n.Filters))
+		case Watermark:
+			b.WriteString("Watermark")
+		case AspectLocked:
+			fmt.Fprintf(&b, "AspectLocked ratio=%g", n.AspectRatio)
+		case Grid:
+			fmt.Fprintf(&b, "Grid rows=%d columns=%d", len(n.Rows), len(n.Columns))
+		case Canvas:
+			fmt.Fprintf(&b, "Canvas items=%d", len(n.Items))
+		default:
+			fmt.Fprintf(&b, "%T", n)
+		}
+		fmt.Fprintf(&b, " rect=%v\n", rect)
+		rects := childRects(n, rect, borderWidth)
+		for i, child := range Children(n) {
+			dump(child, rects[i], depth+1)
+		}
+	}
+	dump(n, image.Rect(0, 0, width, height), 0)
+	return b.String()
+}
+
+// writeConstraint appends the constraint of the named child of a split, if it has one
+func writeConstraint(b *strings.Builder, name string, c Constraint) {
+	if !c.isZero() {
+		fmt.Fprintf(b, " %s=%v", name, c)
+	}
+}
+
+var (
+	wireframeBackground = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+	wireframeCell       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	wireframeCustomCell = color.RGBA{0xff, 0xf4, 0xd6, 0xff}
+	wireframeLine       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// DrawWireframe draws the layout of the tree without the pictures, so that the layout logic could be reviewed without
+// real images. Every picture is drawn as an outlined box labelled with its number, in the same order that Placements
+// uses, and the size of its image. Custom Node implementations are drawn as boxes labelled with their type. A
+// borderWidth of 0 gives the layout of Draw and any other width the layout of DrawWithBorder.
+func DrawWireframe(n Node, width, height, borderWidth int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(wireframeBackground), image.ZP, draw.Src)
+	pictures := 0
+	Walk(n, width, height, borderWidth, func(n Node, rect image.Rectangle) bool {
+		switch n := n.(type) {
+		case Picture:
+			label := fmt.Sprintf("#%d", pictures)
+			if n.Picture != nil {
+				label += fmt.Sprintf(" %dx%d", n.Picture.Bounds().Dx(), n.Picture.Bounds().Dy())
+			}
+			pictures++
+			drawWireframeCell(dst, insetRect(rect, borderWidth), wireframeCell, label)
+		default:
+			if len(Children(n)) == 0 {
+				drawWireframeCell(dst, insetRect(rect, borderWidth), wireframeCustomCell, fmt.Sprintf("%T", n))
+			}
+		}
+		return true
+	})
+	return dst
+}
+
+func drawWireframeCell(dst *image.RGBA, rect image.Rectangle, fill color.Color, label string) {
+	if rect.Empty() {
+		return
+	}
+	draw.Draw(dst, rect, image.NewUniform(fill), image.ZP, draw.Src)
+	line := image.NewUniform(wireframeLine)
+	for _, edge := range []image.Rectangle{
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+1),
+		image.Rect(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y),
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Max.Y),
+		image.Rect(rect.Max.X-1, rect.Min.Y, rect.Max.X, rect.Max.Y),
+	} {
+		draw.Draw(dst, edge.Intersect(rect), line, image.ZP, draw.Src)
+	}
+
+	face := basicfont.Face7x13
+	textWidth := font.MeasureString(face, label).Ceil()
+	if i := strings.IndexByte(label, ' '); i >= 0 && textWidth > rect.Dx()-2 {
+		// Keep at least the number of the cell, which comes first, visible in small cells
+		label = label[:i]
+		textWidth = font.MeasureString(face, label).Ceil()
+	}
+	drawer := font.Drawer{
+		// Drawing into a sub-image clips labels that don't fit the cell
+		Dst:  dst.SubImage(rect).(*image.RGBA),
+		Src:  line,
+		Face: face,
+		Dot: fixed.P(
+			rect.Min.X+(rect.Dx()-textWidth)/2,
+			rect.Min.Y+(rect.Dy()+face.Metrics().Ascent.Ceil()-face.Metrics().Descent.Ceil())/2,
+		),
+	}
+	drawer.DrawString(label)
+}
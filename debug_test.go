@@ -0,0 +1,98 @@
+package picasso_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+	"github.com/disintegration/gift"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Debugging", func() {
+	var node Node
+
+	BeforeEach(func() {
+		node = HorizontalSplit{
+			Ratio: 2,
+			Top:   Picture{uniformImage(600, 400, color.Black)},
+			Bottom: VerticalSplit{
+				Ratio: 0.5,
+				Left:  Filtered{Node: Picture{uniformImage(300, 200, color.Black)}, Filters: []gift.Filter{gift.Grayscale()}},
+				Right: customNode{color.Black},
+			},
+		}
+	})
+
+	It("formats trees on a single line", func() {
+		Expect(fmt.Sprint(node)).To(Equal(
+			"HorizontalSplit(2, Picture(600x400), VerticalSplit(0.5, Filtered(1 filter(s), Picture(300x200)), {{0}}))"))
+		Expect(Picture{}.String()).To(Equal("Picture(nil)"))
+	})
+
+	It("dumps trees with the rectangles of the nodes", func() {
+		Expect(DumpTree(node, 300, 150, 0)).To(Equal(
+			"HorizontalSplit ratio=2 rect=(0,0)-(300,150)\n" +
+				"  Picture #0 600x400 rect=(0,0)-(300,100)\n" +
+				"  VerticalSplit ratio=0.5 rect=(0,100)-(300,150)\n" +
+				"    Filtered filters=1 rect=(0,100)-(100,150)\n" +
+				"      Picture #1 300x200 rect=(0,100)-(100,150)\n" +
+				"    picasso_test.customNode rect=(100,100)-(300,150)\n"))
+	})
+
+	It("dumps missing nodes", func() {
+		Expect(DumpTree(VerticalSplit{Ratio: 1, Left: Picture{}}, 100, 100, 0)).To(Equal(
+			"VerticalSplit ratio=1 rect=(0,0)-(100,100)\n" +
+				"  Picture #0 rect=(0,0)-(50,100)\n" +
+				"  <nil>\n"))
+	})
+
+	Describe("DrawWireframe", func() {
+		var (
+			m          image.Image
+			background = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+			white      = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			line       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+		)
+
+		BeforeEach(func() {
+			m = DrawWireframe(node, 300, 150, 2)
+		})
+
+		It("draws the borders as the background", func() {
+			Expect(m.Bounds()).To(Equal(image.Rect(0, 0, 300, 150)))
+			Expect(m.At(0, 0)).To(Equal(background))
+			Expect(m.At(150, 100)).To(Equal(background))
+		})
+
+		It("outlines the cells", func() {
+			for _, p := range Placements(node, 300, 150, 2) {
+				Expect(m.At(p.Rect.Min.X, p.Rect.Min.Y+5)).To(Equal(line))
+				Expect(m.At(p.Rect.Max.X-1, p.Rect.Min.Y+5)).To(Equal(line))
+				Expect(m.At(p.Rect.Min.X+5, p.Rect.Min.Y)).To(Equal(line))
+				Expect(m.At(p.Rect.Min.X+5, p.Rect.Max.Y-1)).To(Equal(line))
+				Expect(m.At(p.Rect.Min.X+2, p.Rect.Min.Y+2)).To(Equal(white))
+			}
+		})
+
+		It("labels the cells", func() {
+			labelPixels := 0
+			for x := 100; x < 200; x++ {
+				for y := 40; y < 60; y++ {
+					if m.At(x, y) == line {
+						labelPixels++
+					}
+				}
+			}
+			Expect(labelPixels).To(BeNumerically(">", 20))
+		})
+
+		It("draws custom nodes as boxes of their own", func() {
+			Expect(m.At(105, 105)).NotTo(Equal(white))
+			Expect(m.At(105, 105)).NotTo(Equal(background))
+		})
+	})
+})
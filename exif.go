@@ -0,0 +1,160 @@
+package picasso
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"io/ioutil"
+
+	"github.com/disintegration/gift"
+)
+
+// ExifOrientation is the value of the EXIF orientation tag, which describes how the stored pixels have to be
+// transformed for the image to be displayed upright. Cameras, and phones in particular, often store photos sideways
+// and rely on viewers to rotate them.
+type ExifOrientation int
+
+// The EXIF orientations, named after the transformation that turns the stored pixels upright.
+const (
+	OrientationNormal ExifOrientation = iota + 1
+	OrientationFlipHorizontal
+	OrientationRotate180
+	OrientationFlipVertical
+	OrientationTranspose
+	OrientationRotate90Clockwise
+	OrientationTransverse
+	OrientationRotate90CounterClockwise
+)
+
+// Apply transforms the image into its upright orientation. The result will have its width and height swapped for
+// orientations that involve a 90 degree rotation, so layouts will see the actual orientation of the photo.
+func (o ExifOrientation) Apply(m image.Image) image.Image {
+	var filter gift.Filter
+	switch o {
+	case OrientationFlipHorizontal:
+		filter = gift.FlipHorizontal()
+	case OrientationRotate180:
+		filter = gift.Rotate180()
+	case OrientationFlipVertical:
+		filter = gift.FlipVertical()
+	case OrientationTranspose:
+		filter = gift.Transpose()
+	case OrientationRotate90Clockwise:
+		// gift rotates counter-clockwise
+		filter = gift.Rotate270()
+	case OrientationTransverse:
+		filter = gift.Transverse()
+	case OrientationRotate90CounterClockwise:
+		filter = gift.Rotate90()
+	default:
+		return m
+	}
+	g := gift.New(filter)
+	dst := image.NewRGBA(g.Bounds(m.Bounds()))
+	g.Draw(dst, m)
+	return dst
+}
+
+// swapsDimensions reports whether applying the orientation swaps the width and the height of an image.
+func (o ExifOrientation) swapsDimensions() bool {
+	return o >= OrientationTranspose && o <= OrientationRotate90CounterClockwise
+}
+
+// DecodeOriented decodes an image just like image.Decode does and then rotates and flips it according to its EXIF
+// orientation, if it has one.
+func DecodeOriented(r io.Reader) (image.Image, string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	m, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	orientation, err := ReadExifOrientation(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return orientation.Apply(m), format, nil
+}
+
+// ReadExifOrientation reads the EXIF orientation of a JPEG image without decoding the image itself. Images without
+// an orientation tag, including ones in other formats, are reported as OrientationNormal. Errors are only returned
+// if reading from r fails.
+func ReadExifOrientation(r io.Reader) (ExifOrientation, error) {
+	br := bufio.NewReader(r)
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(br, soi); err != nil {
+		return OrientationNormal, ignoreEOF(err)
+	}
+	if soi[0] != 0xff || soi[1] != 0xd8 {
+		return OrientationNormal, nil
+	}
+	for {
+		marker := make([]byte, 4)
+		if _, err := io.ReadFull(br, marker); err != nil {
+			return OrientationNormal, ignoreEOF(err)
+		}
+		// Anything else than a marker here, the start of the scan or the end of the image means that there are no
+		// more metadata segments to be found
+		if marker[0] != 0xff || marker[1] == 0xda || marker[1] == 0xd9 {
+			return OrientationNormal, nil
+		}
+		length := int(binary.BigEndian.Uint16(marker[2:])) - 2
+		if length < 0 {
+			return OrientationNormal, nil
+		}
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return OrientationNormal, ignoreEOF(err)
+		}
+		if marker[1] == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return parseExifOrientation(segment[6:]), nil
+		}
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// parseExifOrientation looks for the orientation tag in the first IFD of the TIFF structure that EXIF data is stored in
+func parseExifOrientation(tiff []byte) ExifOrientation {
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 0 || ifd+2 > len(tiff) {
+		return OrientationNormal
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		const orientationTag, shortType = 0x0112, 3
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == shortType {
+			o := ExifOrientation(order.Uint16(tiff[entry+8:]))
+			if o < OrientationNormal || o > OrientationRotate90CounterClockwise {
+				return OrientationNormal
+			}
+			return o
+		}
+	}
+	return OrientationNormal
+}
@@ -0,0 +1,96 @@
+package picasso_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// exifOrientationFixture returns a 64x32 test image that is stored with the given EXIF orientation. When displayed
+// upright it has a red top left, a green top right, a blue bottom left and a white bottom right quadrant.
+func exifOrientationFixture(o ExifOrientation) TestImage {
+	return TestImage(fmt.Sprintf("./test_images/exif_orientation-%d.jpg", o))
+}
+
+func (i TestImage) decodeOriented() image.Image {
+	file, err := os.Open(string(i))
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	image, format, err := DecodeOriented(file)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(format).To(Equal("jpeg"))
+	return image
+}
+
+func (i TestImage) readExifOrientation() ExifOrientation {
+	file, err := os.Open(string(i))
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	o, err := ReadExifOrientation(file)
+	Expect(err).NotTo(HaveOccurred())
+	return o
+}
+
+func expectColorClose(actual, expected color.Color) {
+	r1, g1, b1, _ := actual.RGBA()
+	r2, g2, b2, _ := expected.RGBA()
+	for _, diff := range []float64{float64(r1) - float64(r2), float64(g1) - float64(g2), float64(b1) - float64(b2)} {
+		Expect(math.Abs(diff)).To(BeNumerically("<", 0x1000), fmt.Sprintf("%v is not close to %v", actual, expected))
+	}
+}
+
+var _ = Describe("EXIF orientation", func() {
+	orientations := []ExifOrientation{
+		OrientationNormal,
+		OrientationFlipHorizontal,
+		OrientationRotate180,
+		OrientationFlipVertical,
+		OrientationTranspose,
+		OrientationRotate90Clockwise,
+		OrientationTransverse,
+		OrientationRotate90CounterClockwise,
+	}
+
+	for _, o := range orientations {
+		o := o
+		Context(fmt.Sprintf("with orientation %d", o), func() {
+			It("reads the orientation", func() {
+				Expect(exifOrientationFixture(o).readExifOrientation()).To(Equal(o))
+			})
+
+			It("decodes the image upright", func() {
+				i := exifOrientationFixture(o).decodeOriented()
+				Expect(i.Bounds().Dx()).To(Equal(64))
+				Expect(i.Bounds().Dy()).To(Equal(32))
+				min := i.Bounds().Min
+				expectColorClose(i.At(min.X+8, min.Y+8), color.RGBA{0xff, 0x00, 0x00, 0xff})
+				expectColorClose(i.At(min.X+56, min.Y+8), color.RGBA{0x00, 0xff, 0x00, 0xff})
+				expectColorClose(i.At(min.X+8, min.Y+24), color.RGBA{0x00, 0x00, 0xff, 0xff})
+				expectColorClose(i.At(min.X+56, min.Y+24), color.RGBA{0xff, 0xff, 0xff, 0xff})
+			})
+		})
+	}
+
+	It("treats images without EXIF data as upright", func() {
+		Expect(Bullfight.readExifOrientation()).To(Equal(OrientationNormal))
+		Expect(PictureWithBorder.readExifOrientation()).To(Equal(OrientationNormal))
+	})
+
+	It("lets layouts see the corrected orientation", func() {
+		sideways := exifOrientationFixture(OrientationRotate90Clockwise)
+		Expect(sideways.read().Bounds().Dy()).To(Equal(64))
+
+		// A single landscape picture makes the grid layout landscape as well
+		i := DrawGridLayout([]image.Image{sideways.decodeOriented()}, 100)
+		Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 100, 70)))
+	})
+})
@@ -0,0 +1,206 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// BorderFill can be passed as the border color to DrawWithBorder and all the other functions that take one to paint
+// the borders with an image or a pattern instead of a single color. The fill is drawn continuously across the whole
+// canvas, so that the gutters between the cells look like a single background showing through. Custom Node
+// implementations, SVG documents and PDF documents use the color of the fill itself, which should be representative of
+// the whole fill.
+type BorderFill interface {
+	color.Color
+	// Image returns the fill of a canvas of the given size. The image has to cover the rectangle from (0, 0) to
+	// (width, height).
+	Image(width, height int) image.Image
+}
+
+// ImageFill creates a BorderFill that resizes the image to fill the whole canvas. The color of the fill is the color
+// at the center of the image, so Sources are decoded right away.
+func ImageFill(m image.Image) BorderFill {
+	return imageFill{m, centerColor(m)}
+}
+
+type imageFill struct {
+	image image.Image
+	color color.Color
+}
+
+func (f imageFill) RGBA() (r, g, b, a uint32) {
+	return f.color.RGBA()
+}
+
+func (f imageFill) Image(width, height int) image.Image {
+	return Picture{f.image}.Draw(width, height)
+}
+
+// TiledFill creates a BorderFill that repeats the image across the canvas, starting from the top left corner. The
+// color of the fill is the color at the center of the tile.
+func TiledFill(tile image.Image) BorderFill {
+	return tiledFill{tile, centerColor(tile)}
+}
+
+type tiledFill struct {
+	tile  image.Image
+	color color.Color
+}
+
+func (f tiledFill) RGBA() (r, g, b, a uint32) {
+	return f.color.RGBA()
+}
+
+func (f tiledFill) Image(width, height int) image.Image {
+	tile, err := decodePixels(f.tile)
+	if err != nil || tile.Bounds().Empty() {
+		return image.NewUniform(color.Transparent)
+	}
+	return &patternImage{func(x, y int) color.Color {
+		b := tile.Bounds()
+		return tile.At(b.Min.X+mod(x, b.Dx()), b.Min.Y+mod(y, b.Dy()))
+	}}
+}
+
+// Checkerboard creates a BorderFill of alternating squares of the given size, starting with the first color at the
+// top left corner.
+func Checkerboard(a, b color.Color, size int) BorderFill {
+	if size <= 0 {
+		size = 1
+	}
+	return checkerboard{a, b, size}
+}
+
+type checkerboard struct {
+	a, b color.Color
+	size int
+}
+
+func (f checkerboard) RGBA() (r, g, b, a uint32) {
+	return f.a.RGBA()
+}
+
+func (f checkerboard) Image(width, height int) image.Image {
+	return &patternImage{func(x, y int) color.Color {
+		if (x/f.size+y/f.size)%2 == 0 {
+			return f.a
+		}
+		return f.b
+	}}
+}
+
+// LinearGradient creates a BorderFill that blends from one color to the other across the whole canvas. The angle is in
+// degrees, with 0 going from left to right and 90 from top to bottom.
+func LinearGradient(from, to color.Color, angle float64) BorderFill {
+	return linearGradient{from, to, angle}
+}
+
+type linearGradient struct {
+	from, to color.Color
+	angle    float64
+}
+
+func (f linearGradient) RGBA() (r, g, b, a uint32) {
+	return blend(f.from, f.to, 0.5).RGBA()
+}
+
+func (f linearGradient) Image(width, height int) image.Image {
+	dx, dy := math.Cos(f.angle*math.Pi/180), math.Sin(f.angle*math.Pi/180)
+	// Project the corners onto the direction of the gradient to find where it starts and ends
+	start, end := math.Inf(1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {float64(width), 0}, {0, float64(height)}, {float64(width), float64(height)}} {
+		p := corner[0]*dx + corner[1]*dy
+		start, end = math.Min(start, p), math.Max(end, p)
+	}
+	return &patternImage{func(x, y int) color.Color {
+		p := (float64(x)+0.5)*dx + (float64(y)+0.5)*dy
+		return blend(f.from, f.to, (p-start)/(end-start))
+	}}
+}
+
+// RadialGradient creates a BorderFill that blends from one color at the center of the canvas to the other at its
+// corners.
+func RadialGradient(center, edge color.Color) BorderFill {
+	return radialGradient{center, edge}
+}
+
+type radialGradient struct {
+	center, edge color.Color
+}
+
+func (f radialGradient) RGBA() (r, g, b, a uint32) {
+	return blend(f.center, f.edge, 0.5).RGBA()
+}
+
+func (f radialGradient) Image(width, height int) image.Image {
+	cx, cy := float64(width)/2, float64(height)/2
+	radius := math.Hypot(cx, cy)
+	return &patternImage{func(x, y int) color.Color {
+		return blend(f.center, f.edge, math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)/radius)
+	}}
+}
+
+// patternImage is an infinite image, like image.Uniform, with its colors calculated from the coordinates
+type patternImage struct {
+	at func(x, y int) color.Color
+}
+
+func (p *patternImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (p *patternImage) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (p *patternImage) At(x, y int) color.Color {
+	return p.at(x, y)
+}
+
+// blend mixes the colors, with t going from 0 for only the first color to 1 for only the second one
+func blend(from, to color.Color, t float64) color.Color {
+	t = math.Max(0, math.Min(t, 1))
+	r0, g0, b0, a0 := from.RGBA()
+	r1, g1, b1, a1 := to.RGBA()
+	mix := func(v0, v1 uint32) uint16 {
+		return uint16(float64(v0)*(1-t) + float64(v1)*t + 0.5)
+	}
+	return color.RGBA64{mix(r0, r1), mix(g0, g1), mix(b0, b1), mix(a0, a1)}
+}
+
+func centerColor(m image.Image) color.Color {
+	pixels, err := decodePixels(m)
+	if err != nil || pixels.Bounds().Empty() {
+		return color.Transparent
+	}
+	b := pixels.Bounds()
+	return pixels.At(b.Min.X+b.Dx()/2, b.Min.Y+b.Dy()/2)
+}
+
+func mod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+// drawWithFill draws the node with a Renderer if the borders are painted with a BorderFill. The DrawWithBorder methods
+// of the nodes compose the separately drawn images of their children, which would start the pattern of a fill over in
+// every cell, while a renderer paints the whole tree onto a single canvas, so that the fill is continuous across all
+// the borders. It returns false for plain colors, which the nodes draw themselves.
+func drawWithFill(n Node, width, height int, borderColor color.Color, borderWidth int) (image.Image, bool) {
+	if _, ok := borderColor.(BorderFill); !ok {
+		return nil, false
+	}
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth), true
+}
+
+// borderImage returns the image that the borders of a canvas of the given size are painted with
+func borderImage(borderColor color.Color, width, height int) image.Image {
+	if f, ok := borderColor.(BorderFill); ok {
+		return f.Image(width, height)
+	}
+	return image.NewUniform(borderColor)
+}
@@ -0,0 +1,139 @@
+package picasso_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+
+	. "github.com/deiwin/picasso"
+	"golang.org/x/image/font/gofont/goregular"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BorderFill", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		blue  = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		green = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		node  Node
+	)
+
+	BeforeEach(func() {
+		node = VerticalSplit{
+			Ratio: 1,
+			Left:  Picture{uniformImage(50, 100, white)},
+			Right: HorizontalSplit{
+				Ratio:  1,
+				Top:    Picture{uniformImage(50, 50, white)},
+				Bottom: Picture{uniformImage(50, 50, white)},
+			},
+		}
+	})
+
+	// expectBorder checks that every pixel that isn't covered by a picture has the color of the fill at the same
+	// coordinates
+	expectBorder := func(m image.Image, expected func(x, y int) color.Color) {
+		border := 0
+		bounds := m.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if m.At(x, y) == white {
+					continue
+				}
+				border++
+				Expect(m.At(x, y)).To(Equal(color.RGBAModel.Convert(expected(x, y))), "at (%d, %d)", x, y)
+			}
+		}
+		Expect(border).To(BeNumerically(">", 0))
+	}
+
+	It("draws checkerboards continuously across the canvas", func() {
+		fill := Checkerboard(red, blue, 1)
+		m := node.DrawWithBorder(100, 100, fill, 4)
+		expectBorder(m, func(x, y int) color.Color {
+			if (x+y)%2 == 0 {
+				return red
+			}
+			return blue
+		})
+		Expect(Renderer{}.DrawWithBorder(node, 100, 100, fill, 4)).To(Equal(m))
+	})
+
+	It("repeats tiles", func() {
+		tile := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		tile.Set(0, 0, red)
+		tile.Set(1, 0, green)
+		m := node.DrawWithBorder(100, 100, TiledFill(tile), 4)
+		expectBorder(m, func(x, y int) color.Color {
+			if x%2 == 0 {
+				return red
+			}
+			return green
+		})
+	})
+
+	It("resizes images to fill the canvas", func() {
+		background := image.NewRGBA(image.Rect(0, 0, 20, 20))
+		draw.Draw(background, image.Rect(0, 0, 10, 20), image.NewUniform(red), image.ZP, draw.Src)
+		draw.Draw(background, image.Rect(10, 0, 20, 20), image.NewUniform(blue), image.ZP, draw.Src)
+		m := node.DrawWithBorder(100, 100, ImageFill(background), 4)
+		Expect(m.At(1, 50)).To(Equal(red))
+		Expect(m.At(98, 50)).To(Equal(blue))
+	})
+
+	It("draws linear gradients across the whole canvas", func() {
+		m := node.DrawWithBorder(100, 100, LinearGradient(red, blue, 0), 4)
+		left := color.RGBAModel.Convert(m.At(0, 50)).(color.RGBA)
+		middle := color.RGBAModel.Convert(m.At(50, 50)).(color.RGBA)
+		right := color.RGBAModel.Convert(m.At(99, 50)).(color.RGBA)
+		Expect(left.R).To(BeNumerically(">", 0xf0))
+		Expect(middle.R).To(BeNumerically("~", 0x80, 2))
+		Expect(middle.B).To(BeNumerically("~", 0x80, 2))
+		Expect(right.B).To(BeNumerically(">", 0xf0))
+		// The gradient goes from left to right, so the colors don't change vertically
+		Expect(m.At(50, 1)).To(Equal(m.At(50, 98)))
+	})
+
+	It("draws vertical gradients", func() {
+		m := node.DrawWithBorder(100, 100, LinearGradient(red, blue, 90), 4)
+		Expect(m.At(1, 0)).To(Equal(m.At(98, 0)))
+		Expect(color.RGBAModel.Convert(m.At(1, 0)).(color.RGBA).R).To(BeNumerically(">", 0xf0))
+		Expect(color.RGBAModel.Convert(m.At(1, 99)).(color.RGBA).B).To(BeNumerically(">", 0xf0))
+	})
+
+	It("draws radial gradients from the center of the canvas", func() {
+		m := node.DrawWithBorder(100, 100, RadialGradient(red, blue), 4)
+		center := color.RGBAModel.Convert(m.At(50, 50)).(color.RGBA)
+		corner := color.RGBAModel.Convert(m.At(0, 0)).(color.RGBA)
+		Expect(center.R).To(BeNumerically(">", 0xf0))
+		Expect(corner.B).To(BeNumerically(">", 0xf0))
+	})
+
+	It("fills the borders of wrapper nodes", func() {
+		font, err := ParseFont(goregular.TTF)
+		Expect(err).NotTo(HaveOccurred())
+		captioned := Captioned{
+			Node:  node,
+			Lines: []string{"Title"},
+			Style: CaptionStyle{Font: font, Background: white, Color: white, Position: CaptionBelow},
+		}
+		m := captioned.DrawWithBorder(100, 100, Checkerboard(red, blue, 1), 4)
+		expectBorder(m, func(x, y int) color.Color {
+			if (x+y)%2 == 0 {
+				return red
+			}
+			return blue
+		})
+	})
+
+	It("uses the color of the fill in SVG documents", func() {
+		var buf bytes.Buffer
+		o := SVGOptions{BorderColor: Checkerboard(red, blue, 4), BorderWidth: 2}
+		Expect(WriteSVG(&buf, node, 100, 100, o)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`fill="#ff0000"`))
+	})
+})
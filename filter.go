@@ -0,0 +1,43 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/gift"
+)
+
+// Filtered is a Node that applies gift filters, e.g. gift.Grayscale, gift.Sepia, gift.Contrast or gift.GaussianBlur,
+// to all the pictures of the node that it wraps. The filters are applied to every picture separately after it has
+// been cropped and resized to fill its cell, so that effects like blurring don't bleed over to neighbouring cells and
+// the borders between the cells keep their color. Filters that change the size of the image, such as rotations, are
+// not supported. Filters of nested Filtered nodes are applied before the filters of the nodes that contain them and
+// pictures within custom Node implementations are not filtered at all.
+type Filtered struct {
+	Node    Node
+	Filters []gift.Filter
+}
+
+// WithFilters wraps the picture in a Filtered node, see Filtered.
+func (n Picture) WithFilters(filters ...gift.Filter) Filtered {
+	return Filtered{Node: n, Filters: filters}
+}
+
+func (n Filtered) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n Filtered) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// applyFilters returns the picture with the filters applied or the picture itself if there are no filters
+func applyFilters(picture image.Image, filters []gift.Filter) image.Image {
+	if len(filters) == 0 {
+		return picture
+	}
+	g := gift.New(filters...)
+	dst := image.NewRGBA(g.Bounds(picture.Bounds()))
+	g.Draw(dst, picture)
+	return dst
+}
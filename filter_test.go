@@ -0,0 +1,104 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+	"github.com/disintegration/gift"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filtered", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		blue  = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		green = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		gray  = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+	)
+
+	isGray := func(c color.Color) bool {
+		r, g, b, _ := c.RGBA()
+		return r == g && g == b
+	}
+
+	It("filters single pictures", func() {
+		m := Picture{uniformImage(100, 100, red)}.WithFilters(gift.Invert()).Draw(50, 50)
+		Expect(m.Bounds()).To(Equal(image.Rect(0, 0, 50, 50)))
+		Expect(m.At(25, 25)).To(Equal(color.RGBA{0x00, 0xff, 0xff, 0xff}))
+	})
+
+	It("filters all cells except the hero", func() {
+		node := HorizontalSplit{
+			Ratio: 1,
+			Top:   Picture{uniformImage(100, 50, red)},
+			Bottom: Filtered{
+				Node: VerticalSplit{
+					Ratio: 1,
+					Left:  Picture{uniformImage(50, 50, blue)},
+					Right: Picture{uniformImage(50, 50, green)},
+				},
+				Filters: []gift.Filter{gift.Grayscale()},
+			},
+		}
+		m := node.DrawWithBorder(100, 100, gray, 2)
+		Expect(m.At(50, 25)).To(Equal(red))
+		Expect(isGray(m.At(25, 75))).To(BeTrue())
+		Expect(isGray(m.At(75, 75))).To(BeTrue())
+		Expect(m.At(25, 75)).NotTo(Equal(m.At(75, 75)))
+		// The borders keep their color
+		Expect(m.At(50, 75)).To(Equal(gray))
+		Expect(m.At(1, 75)).To(Equal(gray))
+	})
+
+	It("filters every cell separately", func() {
+		node := Filtered{
+			Node: VerticalSplit{
+				Ratio: 1,
+				Left:  Picture{uniformImage(50, 50, blue)},
+				Right: Picture{uniformImage(50, 50, green)},
+			},
+			Filters: []gift.Filter{gift.GaussianBlur(5)},
+		}
+		m := node.Draw(100, 50)
+		// Blurring the composed image would mix the colors next to the boundary
+		Expect(m.At(49, 25)).To(Equal(blue))
+		Expect(m.At(50, 25)).To(Equal(green))
+	})
+
+	It("applies the filters of nested nodes first", func() {
+		grayscaleThenRed := func(c color.Color) color.Color {
+			return Filtered{
+				Node: Filtered{
+					Node:    Picture{uniformImage(10, 10, c)},
+					Filters: []gift.Filter{gift.Grayscale()},
+				},
+				Filters: []gift.Filter{gift.ColorBalance(100, 0, 0)},
+			}.Draw(10, 10).At(5, 5)
+		}
+		r, g, b, _ := grayscaleThenRed(blue).RGBA()
+		// Balancing the colors after desaturating leaves the picture red tinted
+		Expect(r).To(BeNumerically(">", g))
+		Expect(g).To(Equal(b))
+	})
+
+	It("keeps the placements of the wrapped node", func() {
+		node := TopHeavyLayout().Compose([]image.Image{uniformImage(100, 100, red), uniformImage(100, 50, blue)})
+		filtered := Filtered{Node: node, Filters: []gift.Filter{gift.Sepia(100)}}
+		Expect(Placements(filtered, 100, 150, 2)).To(Equal(Placements(node, 100, 150, 2)))
+	})
+
+	It("filters pictures that are drawn through a cache", func() {
+		node := Filtered{
+			Node:    Picture{uniformImage(100, 100, red)},
+			Filters: []gift.Filter{gift.Contrast(-50)},
+		}
+		renderer := Renderer{Cache: NewLRUCache(1 << 20)}
+		first := renderer.Draw(node, 50, 50)
+		Expect(renderer.Draw(node, 50, 50)).To(Equal(first))
+		Expect(first).To(Equal(node.Draw(50, 50)))
+		Expect(first.At(25, 25)).NotTo(Equal(red))
+	})
+})
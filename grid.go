@@ -0,0 +1,154 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Track is the size of a row or a column of a Grid. The sizes exclude the borders drawn by DrawWithBorder, like the
+// sizes of a Constraint.
+type Track struct {
+	// Pixels, if set, is the exact size of the track.
+	Pixels int
+	// Fraction is the share of the size left over from the tracks with a fixed number of pixels that the track gets,
+	// relative to the fractions of the other tracks. Tracks with neither Pixels nor a Fraction get a Fraction of 1.
+	Fraction float32
+}
+
+// PixelTrack creates a track of exactly the given number of pixels.
+func PixelTrack(pixels int) Track {
+	return Track{Pixels: pixels}
+}
+
+// FractionTrack creates a track that gets the given share of the size left over from the tracks with a fixed number
+// of pixels.
+func FractionTrack(fraction float32) Track {
+	return Track{Fraction: fraction}
+}
+
+func (t Track) fraction() float64 {
+	if t.Pixels > 0 {
+		return 0
+	} else if t.Fraction == 0 {
+		return 1
+	}
+	return float64(t.Fraction)
+}
+
+func (t Track) valid() bool {
+	return t.Pixels >= 0 && t.Fraction >= 0 && !math.IsInf(float64(t.Fraction), 0)
+}
+
+// GridCell is a node placed in a Grid. Rows and columns are counted from 0 and the cell covers RowSpan rows starting
+// from Row and ColumnSpan columns starting from Column. Spans of 0 are treated as 1.
+type GridCell struct {
+	Node       Node
+	Row        int
+	Column     int
+	RowSpan    int
+	ColumnSpan int
+}
+
+func (c GridCell) rowSpan() int {
+	if c.RowSpan <= 0 {
+		return 1
+	}
+	return c.RowSpan
+}
+
+func (c GridCell) columnSpan() int {
+	if c.ColumnSpan <= 0 {
+		return 1
+	}
+	return c.ColumnSpan
+}
+
+// Grid is a Node that places its cells on a grid of rows and columns, like a CSS grid, which makes it possible to
+// have cells that span several rows and columns, e.g. a hero picture in the middle of a 3x3 grid. The borders drawn
+// by DrawWithBorder are as wide between the cells as they are around the grid, whether the cells span several tracks
+// or not, and areas of the grid that aren't covered by any cells are painted like the borders. Cells that overlap
+// are drawn in order and the parts of cells that are outside of the grid are cut off.
+type Grid struct {
+	Rows    []Track
+	Columns []Track
+	Cells   []GridCell
+}
+
+func (n Grid) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n Grid) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// cellRects returns the rectangles that the cells, including their borders, are drawn into, in the same order as the
+// cells. Cells that are entirely outside of the grid get empty rectangles.
+func (n Grid) cellRects(width, height, borderWidth int) []image.Rectangle {
+	columns := trackEdges(n.Columns, width, borderWidth)
+	rows := trackEdges(n.Rows, height, borderWidth)
+	full := image.Rect(0, 0, width, height)
+	rects := make([]image.Rectangle, len(n.Cells))
+	for i, c := range n.Cells {
+		firstColumn, lastColumn, ok := spannedTracks(c.Column, c.columnSpan(), len(n.Columns))
+		if !ok {
+			continue
+		}
+		firstRow, lastRow, ok := spannedTracks(c.Row, c.rowSpan(), len(n.Rows))
+		if !ok {
+			continue
+		}
+		rects[i] = image.Rect(
+			columns[firstColumn][0]-borderWidth, rows[firstRow][0]-borderWidth,
+			columns[lastColumn][1]+borderWidth, rows[lastRow][1]+borderWidth,
+		).Intersect(full)
+	}
+	return rects
+}
+
+// paintGaps paints the whole grid like the borders, so that the gutters and the areas that aren't covered by any
+// cells would look the same
+func (n Grid) paintGaps(dst *image.RGBA, rect image.Rectangle, border image.Image, borderWidth int) {
+	if borderWidth > 0 {
+		draw.Draw(dst, rect, border, rect.Min, draw.Over)
+	}
+}
+
+// spannedTracks returns the first and the last of count tracks that a cell starting from the given track and spanning
+// the given number of tracks covers, or false if it doesn't cover any
+func spannedTracks(start, span, count int) (int, int, bool) {
+	first, last := maxInt(start, 0), minInt(start+span, count)-1
+	return first, last, first <= last
+}
+
+// trackEdges returns the start and the end of the content of every track, excluding the borders. Every track has a
+// border on both sides, with the borders of neighbouring tracks overlapping, like those of the children of a split.
+func trackEdges(tracks []Track, size, borderWidth int) [][2]int {
+	free := size - (len(tracks)+1)*borderWidth
+	totalFraction := 0.0
+	for _, t := range tracks {
+		free -= t.Pixels
+		totalFraction += t.fraction()
+	}
+	if free < 0 {
+		free = 0
+	}
+
+	edges := make([][2]int, len(tracks))
+	position := borderWidth
+	// The fractional tracks are rounded by their cumulative positions, so that the rounding errors wouldn't add up
+	fraction := 0.0
+	for i, t := range tracks {
+		trackSize := t.Pixels
+		if f := t.fraction(); f > 0 {
+			start := int(float64(free) * fraction / totalFraction)
+			fraction += f
+			trackSize = int(float64(free)*fraction/totalFraction) - start
+		}
+		edges[i] = [2]int{position, position + trackSize}
+		position += trackSize + borderWidth
+	}
+	return edges
+}
@@ -34,29 +34,77 @@ func getImageOrientation(image image.Image) orientation {
 // This layout is not able to manage some combinations of orientations of the provided images. For the example, it has
 // now way to compose a single portrait and a single landscape image. In these cases, the layout will simply discard the
 // last image in the list.
+//
+// The pictures are resized with FinalQuality, use Renderer.DrawGridLayout to choose another Quality.
 func DrawGridLayout(images []image.Image, width int) image.Image {
-	if len(images) == 0 {
+	node, height, ok := gridLayout{}.composeForWidth(images, width)
+	if !ok {
 		return nil
 	}
-	l := gridLayout{}
-	orientation, node := l.compose(images)
-	height := l.getHeight(orientation, width)
 	return node.Draw(width, height)
 }
 
 // DrawGridLayoutWithBorder does the exact same thing that DrawGridLayout does, but with borders.
 func DrawGridLayoutWithBorder(images []image.Image, width int, borderColor color.Color, borderWidth int) image.Image {
-	if len(images) == 0 {
+	node, height, ok := gridLayout{}.composeForWidth(images, width)
+	if !ok {
 		return nil
 	}
-	l := gridLayout{}
-	orientation, node := l.compose(images)
-	height := l.getHeight(orientation, width)
 	return node.DrawWithBorder(width, height, borderColor, borderWidth)
 }
 
+// DrawGridLayout does the exact same thing that the DrawGridLayout function does, but with the settings of the
+// renderer.
+func (r Renderer) DrawGridLayout(images []image.Image, width int) image.Image {
+	node, height, ok := gridLayout{}.composeForWidth(images, width)
+	if !ok {
+		return nil
+	}
+	return r.Draw(node, width, height)
+}
+
+// DrawGridLayoutWithBorder does the exact same thing that the DrawGridLayoutWithBorder function does, but with the
+// settings of the renderer.
+func (r Renderer) DrawGridLayoutWithBorder(images []image.Image, width int, borderColor color.Color, borderWidth int) image.Image {
+	node, height, ok := gridLayout{}.composeForWidth(images, width)
+	if !ok {
+		return nil
+	}
+	return r.DrawWithBorder(node, width, height, borderColor, borderWidth)
+}
+
+// GridLayout creates the layout that DrawGridLayout draws, so that it could be used wherever a Layout is accepted,
+// e.g. with Paginate and WritePDF. The composed node is wrapped in an AspectLocked node with the aspect ratio of
+// sqrt(2) or 1/sqrt(2), depending on the orientation of the grid, which is also the aspect ratio of the A-series paper
+// sizes. See DrawGridLayout for the combinations of images that the layout can't compose fully.
+func GridLayout() Layout {
+	return gridLayout{}
+}
+
 type gridLayout struct{}
 
+// composeForWidth composes the images into a grid and returns it along with the height that it's drawn at for the
+// given width. It returns false if there are no images to compose.
+func (l gridLayout) composeForWidth(images []image.Image, width int) (Node, int, bool) {
+	if len(images) == 0 {
+		return nil, 0, false
+	}
+	orientation, node := l.compose(images)
+	return node, l.getHeight(orientation, width), true
+}
+
+func (l gridLayout) Compose(images []image.Image) Node {
+	if len(images) == 0 {
+		return nil
+	}
+	orientation, node := l.compose(images)
+	aspectRatio := float32(math.Sqrt2)
+	if orientation == vertical {
+		aspectRatio = 1 / aspectRatio
+	}
+	return AspectLocked{Node: node, AspectRatio: aspectRatio}
+}
+
 func (l gridLayout) getHeight(orientation orientation, width int) int {
 	if orientation == horizontal {
 		return int(float32(width) / math.Sqrt2)
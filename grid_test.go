@@ -0,0 +1,152 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Grid", func() {
+	var (
+		red    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		green  = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		blue   = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		gray   = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		hero   Picture
+		small  Picture
+		thirds []Track
+		grid   Grid
+	)
+
+	BeforeEach(func() {
+		hero = Picture{uniformImage(200, 200, red)}
+		small = Picture{uniformImage(100, 100, green)}
+		thirds = []Track{FractionTrack(1), FractionTrack(1), FractionTrack(1)}
+		grid = Grid{
+			Rows:    thirds,
+			Columns: thirds,
+			Cells: []GridCell{
+				{Node: small, Row: 0, Column: 0},
+				{Node: small, Row: 0, Column: 1},
+				{Node: small, Row: 0, Column: 2},
+				{Node: small, Row: 1, Column: 0},
+				{Node: small, Row: 2, Column: 0},
+				{Node: hero, Row: 1, Column: 1, RowSpan: 2, ColumnSpan: 2},
+			},
+		}
+	})
+
+	It("places cells that span several rows and columns", func() {
+		Expect(placementRects(grid, 300, 300, 0)).To(Equal([]image.Rectangle{
+			image.Rect(0, 0, 100, 100),
+			image.Rect(100, 0, 200, 100),
+			image.Rect(200, 0, 300, 100),
+			image.Rect(0, 100, 100, 200),
+			image.Rect(0, 200, 100, 300),
+			image.Rect(100, 100, 300, 300),
+		}))
+	})
+
+	It("draws borders of the same width across the gutters", func() {
+		Expect(placementRects(grid, 306, 306, 6)).To(Equal([]image.Rectangle{
+			image.Rect(6, 6, 100, 100),
+			image.Rect(106, 6, 200, 100),
+			image.Rect(206, 6, 300, 100),
+			image.Rect(6, 106, 100, 200),
+			image.Rect(6, 206, 100, 300),
+			image.Rect(106, 106, 300, 300),
+		}))
+		i := grid.DrawWithBorder(306, 306, gray, 6)
+		Expect(i.At(99, 150)).To(Equal(green))
+		Expect(i.At(100, 150)).To(Equal(gray))
+		Expect(i.At(105, 150)).To(Equal(gray))
+		Expect(i.At(106, 150)).To(Equal(red))
+		Expect(i.At(299, 299)).To(Equal(red))
+		Expect(i.At(300, 300)).To(Equal(gray))
+	})
+
+	It("mixes tracks of fixed pixels and fractions", func() {
+		n := Grid{
+			Rows:    []Track{FractionTrack(1)},
+			Columns: []Track{PixelTrack(50), FractionTrack(1), FractionTrack(2)},
+			Cells: []GridCell{
+				{Node: small, Column: 0},
+				{Node: small, Column: 1},
+				{Node: small, Column: 2},
+			},
+		}
+		Expect(placementRects(n, 350, 100, 0)).To(Equal([]image.Rectangle{
+			image.Rect(0, 0, 50, 100),
+			image.Rect(50, 0, 150, 100),
+			image.Rect(150, 0, 350, 100),
+		}))
+		Expect(placementRects(n, 370, 100, 5)).To(Equal([]image.Rectangle{
+			image.Rect(5, 5, 55, 95),
+			image.Rect(60, 5, 160, 95),
+			image.Rect(165, 5, 365, 95),
+		}))
+	})
+
+	It("paints the areas that aren't covered by cells like the borders", func() {
+		n := Grid{
+			Rows:    thirds,
+			Columns: thirds,
+			Cells:   []GridCell{{Node: Picture{uniformImage(10, 10, blue)}, Row: 1, Column: 1}},
+		}
+		i := n.DrawWithBorder(306, 306, gray, 6)
+		Expect(i.At(50, 50)).To(Equal(gray))
+		Expect(i.At(150, 150)).To(Equal(blue))
+		Expect(n.Draw(300, 300).At(50, 50)).To(Equal(color.RGBA{}))
+	})
+
+	It("cuts off the parts of cells that are outside of the grid", func() {
+		n := Grid{
+			Rows:    thirds,
+			Columns: thirds,
+			Cells:   []GridCell{{Node: small, Row: 2, Column: 2, RowSpan: 2, ColumnSpan: 2}},
+		}
+		Expect(placementRects(n, 300, 300, 0)).To(Equal([]image.Rectangle{image.Rect(200, 200, 300, 300)}))
+		Expect(Validate(n)).To(MatchError("invalid tree: root: Cells[0] not within the grid"))
+	})
+
+	It("is transformed along with the rest of the tree", func() {
+		mirrored := MirrorHorizontally(grid).(Grid)
+		Expect(mirrored.Cells[0]).To(Equal(GridCell{Node: small, Row: 0, Column: 2}))
+		Expect(mirrored.Cells[5]).To(Equal(GridCell{Node: hero, Row: 1, Column: 0, RowSpan: 2, ColumnSpan: 2}))
+		flipped := FlipVertically(grid).(Grid)
+		Expect(flipped.Cells[4]).To(Equal(GridCell{Node: small, Row: 0, Column: 0}))
+		Expect(flipped.Cells[5]).To(Equal(GridCell{Node: hero, Row: 0, Column: 1, RowSpan: 2, ColumnSpan: 2}))
+		Expect(placementRects(RotateClockwise(grid), 300, 300, 0)).To(Equal([]image.Rectangle{
+			image.Rect(200, 0, 300, 100),
+			image.Rect(200, 100, 300, 200),
+			image.Rect(200, 200, 300, 300),
+			image.Rect(100, 0, 200, 100),
+			image.Rect(0, 0, 100, 100),
+			image.Rect(0, 100, 200, 300),
+		}))
+		Expect(RotateCounterClockwise(RotateClockwise(grid))).To(Equal(grid))
+		Expect(grid.Cells[0]).To(Equal(GridCell{Node: small, Row: 0, Column: 0}))
+	})
+
+	It("is validated and normalized", func() {
+		n := Grid{
+			Rows:    []Track{PixelTrack(-1)},
+			Columns: thirds,
+			Cells:   []GridCell{{Node: Picture{}}, {Node: nil, Column: 1}},
+		}
+		Expect(Validate(n)).To(MatchError("invalid tree: root: invalid Rows[0] {Pixels:-1 Fraction:0}; " +
+			"Cells[0].Node: missing image; Cells[1].Node: missing node"))
+		Expect(Normalize(n)).To(Equal(Grid{Rows: n.Rows, Columns: thirds, Cells: []GridCell{{Node: Picture{}}}}))
+		Expect(Normalize(Grid{Rows: thirds, Columns: thirds, Cells: []GridCell{{}}})).To(BeNil())
+	})
+
+	It("is included in the tree dump", func() {
+		n := Grid{Rows: thirds[:1], Columns: thirds[:2], Cells: []GridCell{{Node: small, Column: 1}}}
+		Expect(DumpTree(n, 200, 100, 0)).To(Equal("Grid rows=1 columns=2 rect=(0,0)-(200,100)\n" +
+			"  Picture #0 100x100 rect=(100,0)-(200,100)\n"))
+	})
+})
@@ -5,6 +5,8 @@ import (
 	"math"
 )
 
+// Layout composes images into a tree of nodes. The composed nodes draw with FinalQuality, use a Renderer to draw them
+// with another Quality.
 type Layout interface {
 	Compose([]image.Image) Node
 }
@@ -0,0 +1,128 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+
+	"github.com/disintegration/gift"
+)
+
+// DrawWidths draws the node at each of the given widths, with heights that keep the given width to height aspect
+// ratio. Every output is identical in geometry to drawing the node at that size with Draw, but the pictures are only
+// resized from their full size sources twice: the cells at the largest size a picture is drawn at are resized straight
+// from the picture, while the picture is downscaled once to the next largest size and the smaller cells are resized
+// from that much smaller intermediate image. This makes rendering a collage at several sizes, e.g. a thumbnail,
+// mobile, desktop and retina version, only a little more expensive than rendering the largest size alone. The cells
+// resized from the intermediate images aren't put into the Cache of the renderer, because they've been resampled
+// twice.
+func (r Renderer) DrawWidths(n Node, aspectRatio float64, widths []int) []image.Image {
+	return r.DrawWidthsWithBorder(n, aspectRatio, widths, nil, 0)
+}
+
+// DrawWidthsWithBorder does the same as DrawWidths, but draws the borders of DrawWithBorder around all the cells.
+// The border width is the same for all the outputs.
+func (r Renderer) DrawWidthsWithBorder(n Node, aspectRatio float64, widths []int, borderColor color.Color, borderWidth int) []image.Image {
+	sizes := make([]image.Point, len(widths))
+	var placements []Placement
+	for i, width := range widths {
+		sizes[i] = image.Pt(width, int(float64(width)/aspectRatio+0.5))
+		placements = append(placements, Placements(n, sizes[i].X, sizes[i].Y, borderWidth)...)
+	}
+
+	r.downscales = r.downscalePictures(placements)
+	borderColor = resolveBorderColor(n, borderColor)
+	images := make([]image.Image, len(sizes))
+	for i, size := range sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+		r.border = borderImage(borderColor, size.X, size.Y)
+		r.paint(dst, n, dst.Bounds(), borderColor, borderWidth)
+		images[i] = dst
+	}
+	return images
+}
+
+// intermediateImage is a smaller version of a picture that the cells of the picture drawn at up to the given scale
+// are resized from
+type intermediateImage struct {
+	image image.Image
+	scale float64
+}
+
+// downscalePictures resizes all pictures that are drawn more than once below the largest scale they're drawn at to
+// the smallest size that still covers every such cell. The cells drawn at the largest scale are resized straight from
+// the pictures, because the intermediate image would be just as large as them and only add another resampling.
+func (r Renderer) downscalePictures(placements []Placement) map[image.Image]intermediateImage {
+	scales := make(map[image.Image][]float64)
+	for _, p := range placements {
+		if !reflect.TypeOf(p.Picture).Comparable() {
+			continue
+		}
+		scales[p.Picture] = append(scales[p.Picture], pictureScale(p.Picture, p.Rect.Dx(), p.Rect.Dy()))
+	}
+
+	downscales := make(map[image.Image]intermediateImage)
+	for picture, pictureScales := range scales {
+		largest := 0.0
+		for _, scale := range pictureScales {
+			largest = math.Max(largest, scale)
+		}
+		smaller, scale := 0, 0.0
+		for _, s := range pictureScales {
+			if s < largest {
+				smaller++
+				scale = math.Max(scale, s)
+			}
+		}
+		// There's nothing to gain from an intermediate image that's only used once or that would be just as large
+		// as the picture itself
+		if smaller < 2 || scale >= 1 {
+			continue
+		}
+		bounds := picture.Bounds()
+		width := int(math.Ceil(float64(bounds.Dx()) * scale))
+		height := int(math.Ceil(float64(bounds.Dy()) * scale))
+		if m, err := r.downscale(picture, width, height); err == nil {
+			downscales[picture] = intermediateImage{image: m, scale: scale}
+		}
+	}
+	return downscales
+}
+
+// downscaled returns the intermediate image that the picture can be resized from to fill the given size, if any
+func (r Renderer) downscaled(p Picture, width, height int) (image.Image, bool) {
+	if p.Picture == nil || !reflect.TypeOf(p.Picture).Comparable() {
+		return nil, false
+	}
+	d, ok := r.downscales[p.Picture]
+	if !ok || pictureScale(p.Picture, width, height) > d.scale {
+		return nil, false
+	}
+	return d.image, true
+}
+
+// pictureScale is the factor that the picture is scaled by to fill an area of the given size
+func pictureScale(picture image.Image, width, height int) float64 {
+	bounds := picture.Bounds()
+	return math.Max(
+		float64(width)/float64(bounds.Dx()),
+		float64(height)/float64(bounds.Dy()),
+	)
+}
+
+func (r Renderer) downscale(picture image.Image, width, height int) (image.Image, error) {
+	pixels, err := decodePixels(picture)
+	if err != nil {
+		return nil, err
+	}
+	if r.Quality == DraftQuality {
+		pixels = preShrink(pixels, pixels.Bounds().Dx(), width)
+	}
+	g := gift.New(
+		gift.Resize(width, height, r.Quality.resampling()),
+	)
+	dst := image.NewRGBA(g.Bounds(pixels.Bounds()))
+	g.Draw(dst, pixels)
+	return dst, nil
+}
@@ -0,0 +1,134 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrawWidths", func() {
+	var (
+		red    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		widths = []int{60, 150, 300}
+		images []image.Image
+	)
+
+	BeforeEach(func() {
+		images = []image.Image{
+			GirlBeforeAMirror.read(),
+			OldGuitarist.read(),
+			WomenOfAlgiers.read(),
+			Bullfight.read(),
+		}
+	})
+
+	// meanDifference is the average difference between the channels of the pixels of two images of the same size
+	meanDifference := func(a, b image.Image) float64 {
+		var sum, count float64
+		bounds := a.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				ar, ag, ab, _ := a.At(x, y).RGBA()
+				br, bg, bb, _ := b.At(x, y).RGBA()
+				for _, d := range []int{int(ar) - int(br), int(ag) - int(bg), int(ab) - int(bb)} {
+					if d < 0 {
+						d = -d
+					}
+					sum += float64(d >> 8)
+					count++
+				}
+			}
+		}
+		return sum / count
+	}
+
+	It("draws the same layout at every width", func() {
+		node := TopHeavyLayout().Compose(images)
+		drawn := Renderer{}.DrawWidthsWithBorder(node, 1.5, widths, red, 2)
+		Expect(drawn).To(HaveLen(3))
+		for i, width := range widths {
+			expected := node.DrawWithBorder(width, width*2/3, red, 2)
+			Expect(drawn[i].Bounds()).To(Equal(expected.Bounds()))
+			Expect(meanDifference(drawn[i], expected)).To(BeNumerically("<", 2))
+			for _, p := range Placements(node, width, width*2/3, 2) {
+				// The borders around the cells are drawn in exactly the same places
+				Expect(drawn[i].At(p.Rect.Min.X-1, p.Rect.Min.Y-1)).To(Equal(red))
+				Expect(drawn[i].At(p.Rect.Max.X, p.Rect.Max.Y)).To(Equal(red))
+			}
+		}
+	})
+
+	It("draws uniform pictures exactly like Draw does", func() {
+		node := GoldenSpiralLayout().Compose([]image.Image{
+			uniformImage(3000, 2000, color.RGBA{0x00, 0xff, 0x00, 0xff}),
+			uniformImage(200, 300, color.RGBA{0x00, 0x00, 0xff, 0xff}),
+			uniformImage(500, 500, color.RGBA{0x00, 0xff, 0xff, 0xff}),
+		})
+		drawn := Renderer{}.DrawWidths(node, 1.5, widths)
+		for i, width := range widths {
+			Expect(drawn[i]).To(Equal(node.Draw(width, width*2/3)))
+		}
+	})
+
+	It("draws a single width exactly like Draw does", func() {
+		node := TopHeavyLayout().Compose(images)
+		Expect(Renderer{}.DrawWidths(node, 1.5, []int{300})).To(Equal([]image.Image{node.Draw(300, 200)}))
+	})
+
+	It("draws the largest width straight from the sources", func() {
+		node := TopHeavyLayout().Compose(images)
+		Expect(Renderer{}.DrawWidths(node, 1.5, widths)[2]).To(Equal(node.Draw(300, 200)))
+	})
+
+	It("doesn't cache the pictures resized from the intermediate images", func() {
+		node := TopHeavyLayout().Compose(images)
+		r := Renderer{Cache: NewLRUCache(1 << 24)}
+		r.DrawWidths(node, 1.5, widths)
+		for _, width := range widths {
+			Expect(r.Draw(node, width, width*2/3)).To(Equal(node.Draw(width, width*2/3)))
+		}
+	})
+})
+
+func benchmarkImages(b *testing.B) []image.Image {
+	var images []image.Image
+	for _, testImage := range []TestImage{GirlBeforeAMirror, OldGuitarist, WomenOfAlgiers, Bullfight} {
+		file, err := os.Open(string(testImage))
+		if err != nil {
+			b.Fatal(err)
+		}
+		m, _, err := image.Decode(file)
+		file.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+		images = append(images, m)
+	}
+	return images
+}
+
+var benchmarkWidths = []int{80, 160, 320, 640}
+
+func BenchmarkDrawWidths(b *testing.B) {
+	node := TopHeavyLayout().Compose(benchmarkImages(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Renderer{}.DrawWidths(node, 1.5, benchmarkWidths)
+	}
+}
+
+func BenchmarkDrawRepeatedly(b *testing.B) {
+	node := TopHeavyLayout().Compose(benchmarkImages(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, width := range benchmarkWidths {
+			node.Draw(width, int(float64(width)/1.5+0.5))
+		}
+	}
+}
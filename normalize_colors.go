@@ -0,0 +1,227 @@
+package picasso
+
+import (
+	"image"
+	"math"
+	"reflect"
+
+	"github.com/disintegration/gift"
+)
+
+// ColorMethod determines how a ColorNormalizer matches the colors of pictures.
+type ColorMethod int
+
+const (
+	// MatchExposure scales the brightness of every picture, so that all of them would have the same average
+	// luminance.
+	MatchExposure ColorMethod = iota
+	// MatchWhiteBalance scales every color channel of every picture separately, so that all of them would have the
+	// same average color. This removes color casts as well as differences in exposure.
+	MatchWhiteBalance
+	// MatchHistogram remaps the values of every color channel of every picture, so that all of them would have the
+	// same distribution of values. It's the strongest of the methods and also matches contrast.
+	MatchHistogram
+)
+
+// maxColorSamples is the number of pixels that are sampled from every picture to analyse its colors
+const maxColorSamples = 1 << 16
+
+// ColorNormalizer makes pictures that have been taken with different cameras or in different lighting look consistent
+// next to each other. It analyses the colors of all the pictures of a tree and adjusts every picture toward a common
+// target with a Filtered node. The adjustments only depend on the pictures, so the output is deterministic.
+type ColorNormalizer struct {
+	Method ColorMethod
+	// Reference, if set, is the image whose colors all the pictures are matched to. Otherwise the pictures are
+	// matched to the average of all of them.
+	Reference image.Image
+}
+
+// Normalize returns the tree with every picture wrapped in a Filtered node that adjusts its colors. Pictures that
+// can't be decoded are left as they are. Pictures within custom Node implementations are not adjusted.
+func (c ColorNormalizer) Normalize(n Node) Node {
+	pictures := treePictures(n)
+
+	// Pictures that occur more than once are only analysed once and only count once toward the average
+	analysed := make(map[image.Image]*colorStats)
+	stats := make([]*colorStats, len(pictures))
+	var distinct []*colorStats
+	for i, picture := range pictures {
+		comparable := reflect.TypeOf(picture).Comparable()
+		if comparable {
+			if s, ok := analysed[picture]; ok {
+				stats[i] = s
+				continue
+			}
+		}
+		stats[i] = analyseColors(picture)
+		if comparable {
+			analysed[picture] = stats[i]
+		}
+		if stats[i] != nil {
+			distinct = append(distinct, stats[i])
+		}
+	}
+
+	target := averageColorStats(distinct)
+	if c.Reference != nil {
+		target = analyseColors(c.Reference)
+	}
+	if target == nil {
+		return n
+	}
+
+	i := 0
+	return replacePictures(n, func(p Picture) Node {
+		s := stats[i]
+		i++
+		if s == nil {
+			return p
+		}
+		return p.WithFilters(c.filter(s, target))
+	})
+}
+
+// Layout returns a layout that composes the images with the given layout and then normalizes the colors of the
+// composed tree, see Normalize.
+func (c ColorNormalizer) Layout(l Layout) Layout {
+	return normalizedLayout{l, c}
+}
+
+type normalizedLayout struct {
+	layout     Layout
+	normalizer ColorNormalizer
+}
+
+func (l normalizedLayout) Compose(images []image.Image) Node {
+	n := l.layout.Compose(images)
+	if n == nil {
+		return nil
+	}
+	return l.normalizer.Normalize(n)
+}
+
+// filter creates a filter that maps the colors of a picture with the given stats toward the target
+func (c ColorNormalizer) filter(s, target *colorStats) gift.Filter {
+	var lut [3][256]float32
+	switch c.Method {
+	case MatchHistogram:
+		for channel := range lut {
+			lut[channel] = matchHistogram(s.histograms[channel], target.histograms[channel])
+		}
+	case MatchWhiteBalance:
+		for channel := range lut {
+			lut[channel] = gainLUT(ratio(target.means[channel], s.means[channel]))
+		}
+	default:
+		gain := gainLUT(ratio(target.luminance(), s.luminance()))
+		for channel := range lut {
+			lut[channel] = gain
+		}
+	}
+	return gift.ColorFunc(func(r, g, b, a float32) (float32, float32, float32, float32) {
+		return lut[0][lutIndex(r)], lut[1][lutIndex(g)], lut[2][lutIndex(b)], a
+	})
+}
+
+// colorStats describes the distribution of the values of the red, green and blue channels of an image
+type colorStats struct {
+	// histograms has the share of samples with every value of every channel
+	histograms [3][256]float64
+	means      [3]float64
+}
+
+// analyseColors samples an evenly spaced grid of pixels of the image. It returns nil for Sources that can't be decoded
+// and for empty images.
+func analyseColors(m image.Image) *colorStats {
+	pixels, err := decodePixels(m)
+	if err != nil {
+		return nil
+	}
+	bounds := pixels.Bounds()
+	if bounds.Empty() {
+		return nil
+	}
+	step := int(math.Ceil(math.Sqrt(float64(bounds.Dx()*bounds.Dy()) / maxColorSamples)))
+	s := &colorStats{}
+	count := 0.0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, _ := pixels.At(x, y).RGBA()
+			s.histograms[0][r>>8]++
+			s.histograms[1][g>>8]++
+			s.histograms[2][b>>8]++
+			count++
+		}
+	}
+	for channel := range s.histograms {
+		for value := range s.histograms[channel] {
+			s.histograms[channel][value] /= count
+			s.means[channel] += float64(value) * s.histograms[channel][value]
+		}
+	}
+	return s
+}
+
+func averageColorStats(stats []*colorStats) *colorStats {
+	if len(stats) == 0 {
+		return nil
+	}
+	average := &colorStats{}
+	for _, s := range stats {
+		for channel := range s.histograms {
+			for value, share := range s.histograms[channel] {
+				average.histograms[channel][value] += share / float64(len(stats))
+			}
+			average.means[channel] += s.means[channel] / float64(len(stats))
+		}
+	}
+	return average
+}
+
+func (s *colorStats) luminance() float64 {
+	return 0.299*s.means[0] + 0.587*s.means[1] + 0.114*s.means[2]
+}
+
+func ratio(target, actual float64) float64 {
+	if actual == 0 {
+		return 1
+	}
+	return target / actual
+}
+
+// gainLUT creates a lookup table that multiplies values by the gain
+func gainLUT(gain float64) [256]float32 {
+	var lut [256]float32
+	for value := range lut {
+		lut[value] = float32(math.Min(float64(value)*gain, 255) / 255)
+	}
+	return lut
+}
+
+// matchHistogram creates a lookup table that maps every value to the smallest target value that has at least the same
+// cumulative share of samples
+func matchHistogram(histogram, target [256]float64) [256]float32 {
+	var lut [256]float32
+	cumulative, targetCumulative := 0.0, target[0]
+	targetValue := 0
+	for value := range lut {
+		cumulative += histogram[value]
+		// A small tolerance keeps rounding errors from pushing values to the next level
+		for targetCumulative < cumulative-1e-9 && targetValue < 255 {
+			targetValue++
+			targetCumulative += target[targetValue]
+		}
+		lut[value] = float32(targetValue) / 255
+	}
+	return lut
+}
+
+func lutIndex(v float32) int {
+	i := int(v*255 + 0.5)
+	if i < 0 {
+		return 0
+	} else if i > 255 {
+		return 255
+	}
+	return i
+}
@@ -0,0 +1,126 @@
+package picasso_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ColorNormalizer", func() {
+	gray := func(v uint8) color.RGBA {
+		return color.RGBA{v, v, v, 0xff}
+	}
+
+	// halvesImage has the left half in one color and the right half in another
+	halvesImage := func(left, right color.Color) image.Image {
+		m := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(m, image.Rect(0, 0, 50, 100), image.NewUniform(left), image.ZP, draw.Src)
+		draw.Draw(m, image.Rect(50, 0, 100, 100), image.NewUniform(right), image.ZP, draw.Src)
+		return m
+	}
+
+	expectColor := func(c color.Color, r, g, b uint8) {
+		actual := color.RGBAModel.Convert(c).(color.RGBA)
+		Expect(actual.R).To(BeNumerically("~", r, 1))
+		Expect(actual.G).To(BeNumerically("~", g, 1))
+		Expect(actual.B).To(BeNumerically("~", b, 1))
+	}
+
+	split := func(left, right image.Image) Node {
+		return VerticalSplit{Ratio: 1, Left: Picture{left}, Right: Picture{right}}
+	}
+
+	It("matches the exposure of all the pictures", func() {
+		node := ColorNormalizer{Method: MatchExposure}.Normalize(split(
+			uniformImage(100, 100, gray(50)),
+			uniformImage(100, 100, gray(150)),
+		))
+		m := node.Draw(200, 100)
+		expectColor(m.At(50, 50), 100, 100, 100)
+		expectColor(m.At(150, 50), 100, 100, 100)
+	})
+
+	It("matches the white balance of all the pictures", func() {
+		node := ColorNormalizer{Method: MatchWhiteBalance}.Normalize(split(
+			uniformImage(100, 100, color.RGBA{200, 100, 100, 0xff}),
+			uniformImage(100, 100, color.RGBA{100, 100, 200, 0xff}),
+		))
+		m := node.Draw(200, 100)
+		expectColor(m.At(50, 50), 150, 100, 150)
+		expectColor(m.At(150, 50), 150, 100, 150)
+	})
+
+	It("matches the histograms of all the pictures", func() {
+		node := ColorNormalizer{Method: MatchHistogram}.Normalize(split(
+			halvesImage(gray(0), gray(100)),
+			halvesImage(gray(100), gray(200)),
+		))
+		m := node.Draw(200, 100)
+		expectColor(m.At(25, 50), 100, 100, 100)
+		expectColor(m.At(75, 50), 200, 200, 200)
+		expectColor(m.At(125, 50), 100, 100, 100)
+		expectColor(m.At(175, 50), 200, 200, 200)
+	})
+
+	It("matches the pictures to a reference", func() {
+		node := ColorNormalizer{
+			Method:    MatchWhiteBalance,
+			Reference: uniformImage(10, 10, color.RGBA{120, 60, 30, 0xff}),
+		}.Normalize(split(
+			uniformImage(100, 100, gray(60)),
+			uniformImage(100, 100, gray(240)),
+		))
+		m := node.Draw(200, 100)
+		expectColor(m.At(50, 50), 120, 60, 30)
+		expectColor(m.At(150, 50), 120, 60, 30)
+	})
+
+	It("produces deterministic output for photos", func() {
+		images := []image.Image{
+			GirlBeforeAMirror.read(),
+			OldGuitarist.read(),
+			WomenOfAlgiers.read(),
+			Bullfight.read(),
+		}
+		for _, method := range []ColorMethod{MatchExposure, MatchWhiteBalance, MatchHistogram} {
+			layout := ColorNormalizer{Method: method}.Layout(TopHeavyLayout())
+			first := layout.Compose(images).Draw(200, 300)
+			second := layout.Compose(images).Draw(200, 300)
+			Expect(first).To(Equal(second))
+			Expect(first).NotTo(Equal(TopHeavyLayout().Compose(images).Draw(200, 300)))
+		}
+	})
+
+	It("keeps the placements of the pictures", func() {
+		images := []image.Image{uniformImage(100, 100, gray(50)), uniformImage(100, 50, gray(150))}
+		node := TopHeavyLayout().Compose(images)
+		normalized := ColorNormalizer{}.Layout(TopHeavyLayout()).Compose(images)
+		Expect(Placements(normalized, 100, 150, 2)).To(Equal(Placements(node, 100, 150, 2)))
+	})
+
+	It("leaves the pictures that can't be decoded as they are", func() {
+		var encoded bytes.Buffer
+		Expect(png.Encode(&encoded, uniformImage(100, 100, gray(50)))).To(Succeed())
+		opened := false
+		source, err := NewSource(func() (io.ReadCloser, error) {
+			if opened {
+				return nil, errors.New("gone")
+			}
+			opened = true
+			return ioutil.NopCloser(bytes.NewReader(encoded.Bytes())), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		node := ColorNormalizer{}.Normalize(split(source, uniformImage(100, 100, gray(150))))
+		Expect(node.(VerticalSplit).Left).To(Equal(Picture{source}))
+	})
+})
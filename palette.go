@@ -0,0 +1,239 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// AutoBorderColor can be passed as the border color to DrawWithBorder, DrawGridLayoutWithBorder and all the other
+// functions that take one to have the border color derived from the pictures of the drawn tree. The color is a muted
+// version of the dominant color of the pictures, see Palette and HarmoniousColor. Custom Node implementations that
+// get it as a border color see the same gray that the README uses.
+var AutoBorderColor color.Color = autoBorderColor{}
+
+type autoBorderColor struct{}
+
+func (autoBorderColor) RGBA() (r, g, b, a uint32) {
+	return fallbackBorderColor.RGBA()
+}
+
+// fallbackBorderColor is used when there are no pictures to derive a border color from
+var fallbackBorderColor = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+
+// paletteSize is the number of colors that AutoBorderColor picks the dominant color from
+const paletteSize = 8
+
+// resolveBorderColor replaces the AutoBorderColor with a color derived from the pictures of the tree. Nodes resolve
+// the color before passing it on to their children, so the color of the whole tree is derived from all of its
+// pictures.
+func resolveBorderColor(n Node, borderColor color.Color) color.Color {
+	if _, ok := borderColor.(autoBorderColor); !ok {
+		return borderColor
+	}
+	return HarmoniousColor(Palette(treePictures(n), paletteSize))
+}
+
+// treePictures returns the images of all the pictures of the tree in depth-first order
+func treePictures(n Node) []image.Image {
+	var pictures []image.Image
+	Visit(n, func(n Node) bool {
+		if p, ok := n.(Picture); ok {
+			pictures = append(pictures, p.Picture)
+		}
+		return true
+	})
+	return pictures
+}
+
+// Palette extracts up to size dominant colors from the images with the median cut algorithm. The colors are ordered
+// from the most to the least common. Up to a fixed number of evenly spaced pixels are sampled from every image, so
+// large images aren't any slower to analyse than small ones and the result is deterministic. Sources that can't be
+// decoded are skipped.
+func Palette(images []image.Image, size int) []color.Color {
+	var samples [][3]uint8
+	for _, m := range images {
+		samples = appendColorSamples(samples, m)
+	}
+	if len(samples) == 0 || size <= 0 {
+		return nil
+	}
+
+	boxes := []colorBox{{samples}}
+	for len(boxes) < size {
+		// Split the box with the widest range of values in any channel
+		widest, widestRange := -1, 0
+		for i, box := range boxes {
+			if _, r := box.widestChannel(); r > widestRange {
+				widest, widestRange = i, r
+			}
+		}
+		if widest < 0 {
+			// All the boxes have a single color left
+			break
+		}
+		low, high := boxes[widest].split()
+		boxes[widest] = low
+		boxes = append(boxes, high)
+	}
+
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return len(boxes[i].samples) > len(boxes[j].samples)
+	})
+	palette := make([]color.Color, len(boxes))
+	for i, box := range boxes {
+		palette[i] = box.average()
+	}
+	return palette
+}
+
+func appendColorSamples(samples [][3]uint8, m image.Image) [][3]uint8 {
+	pixels, err := decodePixels(m)
+	if err != nil {
+		return samples
+	}
+	bounds := pixels.Bounds()
+	if bounds.Empty() {
+		return samples
+	}
+	step := int(math.Ceil(math.Sqrt(float64(bounds.Dx()*bounds.Dy()) / maxColorSamples)))
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := color.NRGBAModel.Convert(pixels.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				continue
+			}
+			samples = append(samples, [3]uint8{c.R, c.G, c.B})
+		}
+	}
+	return samples
+}
+
+// colorBox is a group of samples in the median cut algorithm
+type colorBox struct {
+	samples [][3]uint8
+}
+
+// widestChannel returns the channel with the widest range of values and the range
+func (b colorBox) widestChannel() (int, int) {
+	min := [3]uint8{0xff, 0xff, 0xff}
+	var max [3]uint8
+	for _, s := range b.samples {
+		for channel, v := range s {
+			if v < min[channel] {
+				min[channel] = v
+			}
+			if v > max[channel] {
+				max[channel] = v
+			}
+		}
+	}
+	widest, widestRange := 0, 0
+	for channel := range min {
+		if r := int(max[channel]) - int(min[channel]); r > widestRange {
+			widest, widestRange = channel, r
+		}
+	}
+	return widest, widestRange
+}
+
+// split splits the box at the median of its widest channel
+func (b colorBox) split() (colorBox, colorBox) {
+	channel, _ := b.widestChannel()
+	sort.SliceStable(b.samples, func(i, j int) bool {
+		return b.samples[i][channel] < b.samples[j][channel]
+	})
+	median := len(b.samples) / 2
+	// Keep samples with the same value in the same box, so that a single color wouldn't end up in both halves
+	for median > 0 && b.samples[median-1][channel] == b.samples[median][channel] {
+		median--
+	}
+	if median == 0 {
+		for median < len(b.samples) && b.samples[median][channel] == b.samples[0][channel] {
+			median++
+		}
+	}
+	return colorBox{b.samples[:median]}, colorBox{b.samples[median:]}
+}
+
+func (b colorBox) average() color.Color {
+	var sum [3]float64
+	for _, s := range b.samples {
+		for channel, v := range s {
+			sum[channel] += float64(v)
+		}
+	}
+	n := float64(len(b.samples))
+	return color.RGBA{
+		uint8(sum[0]/n + 0.5),
+		uint8(sum[1]/n + 0.5),
+		uint8(sum[2]/n + 0.5),
+		0xff,
+	}
+}
+
+// HarmoniousColor returns a muted version of the first, i.e. the most dominant, color of the palette, which makes for
+// a border or a background color that goes well with the images of the palette without drawing attention to itself.
+func HarmoniousColor(palette []color.Color) color.Color {
+	if len(palette) == 0 {
+		return fallbackBorderColor
+	}
+	h, s, l := toHSL(palette[0])
+	return fromHSL(h, math.Min(s, 0.25), math.Max(0.3, math.Min(l, 0.8)))
+}
+
+// toHSL converts the color to its hue in degrees and its saturation and lightness between 0 and 1
+func toHSL(c color.Color) (float64, float64, float64) {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	r, g, b := float64(rgba.R)/0xff, float64(rgba.G)/0xff, float64(rgba.B)/0xff
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l := (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+	d := max - min
+	s := d / (1 - math.Abs(2*l-1))
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+func fromHSL(h, s, l float64) color.Color {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return color.RGBA{
+		uint8((r+m)*0xff + 0.5),
+		uint8((g+m)*0xff + 0.5),
+		uint8((b+m)*0xff + 0.5),
+		0xff,
+	}
+}
@@ -0,0 +1,116 @@
+package picasso_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Palette", func() {
+	var (
+		red   = color.RGBA{0xc0, 0x20, 0x20, 0xff}
+		green = color.RGBA{0x20, 0xc0, 0x20, 0xff}
+		blue  = color.RGBA{0x20, 0x20, 0xc0, 0xff}
+	)
+
+	// stripesImage has stripes of the given colors with the given widths
+	stripesImage := func(colors []color.Color, widths []int) image.Image {
+		total := 0
+		for _, width := range widths {
+			total += width
+		}
+		m := image.NewRGBA(image.Rect(0, 0, total, 10))
+		x := 0
+		for i, c := range colors {
+			draw.Draw(m, image.Rect(x, 0, x+widths[i], 10), image.NewUniform(c), image.ZP, draw.Src)
+			x += widths[i]
+		}
+		return m
+	}
+
+	It("orders the dominant colors by how common they are", func() {
+		images := []image.Image{
+			stripesImage([]color.Color{green, red}, []int{20, 50}),
+			uniformImage(30, 10, blue),
+		}
+		Expect(Palette(images, 3)).To(Equal([]color.Color{red, blue, green}))
+	})
+
+	It("returns fewer colors if the images don't have more", func() {
+		Expect(Palette([]image.Image{uniformImage(10, 10, red)}, 5)).To(Equal([]color.Color{red}))
+	})
+
+	It("returns nothing for no images", func() {
+		Expect(Palette(nil, 5)).To(BeEmpty())
+	})
+
+	It("is deterministic for photos", func() {
+		images := []image.Image{GirlBeforeAMirror.read(), OldGuitarist.read()}
+		palette := Palette(images, 8)
+		Expect(palette).To(HaveLen(8))
+		Expect(Palette(images, 8)).To(Equal(palette))
+	})
+
+	Describe("HarmoniousColor", func() {
+		It("mutes the dominant color", func() {
+			c := color.RGBAModel.Convert(HarmoniousColor([]color.Color{red, blue})).(color.RGBA)
+			Expect(c.R).To(BeNumerically(">", c.G))
+			Expect(c.G).To(Equal(c.B))
+			// The saturation is much lower than that of the dominant color
+			Expect(int(c.R) - int(c.G)).To(BeNumerically("<", 0xc0-0x20))
+		})
+
+		It("falls back to gray", func() {
+			Expect(HarmoniousColor(nil)).To(Equal(color.RGBA{0xaf, 0xaf, 0xaf, 0xff}))
+		})
+	})
+
+	Describe("AutoBorderColor", func() {
+		var (
+			images []image.Image
+			node   Node
+			border color.Color
+		)
+
+		BeforeEach(func() {
+			images = []image.Image{
+				uniformImage(100, 50, red),
+				uniformImage(50, 100, red),
+				uniformImage(100, 100, blue),
+			}
+			node = TopHeavyLayout().Compose(images)
+			border = HarmoniousColor(Palette(images, 8))
+		})
+
+		It("derives the border color from the pictures of the tree", func() {
+			m := node.DrawWithBorder(100, 100, AutoBorderColor, 2)
+			Expect(m.At(0, 0)).To(Equal(border))
+			Expect(m).To(Equal(node.DrawWithBorder(100, 100, border, 2)))
+		})
+
+		It("uses the same color in nested nodes", func() {
+			m := node.(HorizontalSplit).DrawWithBorder(100, 100, AutoBorderColor, 2)
+			// The bottom cells have different pictures than the whole tree, but share its border color
+			Expect(m.At(99, 99)).To(Equal(border))
+		})
+
+		It("works with renderers and the grid layout", func() {
+			Expect(Renderer{}.DrawWithBorder(node, 100, 100, AutoBorderColor, 2)).
+				To(Equal(node.DrawWithBorder(100, 100, border, 2)))
+			Expect(DrawGridLayoutWithBorder(images, 100, AutoBorderColor, 2)).
+				To(Equal(DrawGridLayoutWithBorder(images, 100, border, 2)))
+		})
+
+		It("fills the border of SVG documents", func() {
+			var buf bytes.Buffer
+			Expect(WriteSVG(&buf, node, 100, 100, SVGOptions{BorderColor: AutoBorderColor, BorderWidth: 2})).To(Succeed())
+			Expect(buf.String()).NotTo(ContainSubstring("#afafaf"))
+		})
+	})
+})
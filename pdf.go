@@ -0,0 +1,326 @@
+package picasso
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"reflect"
+)
+
+// PaperSize is the physical size of a page.
+type PaperSize struct {
+	Width  Length
+	Height Length
+}
+
+// Commonly used paper sizes in portrait orientation.
+var (
+	A3     = PaperSize{297 * Millimetre, 420 * Millimetre}
+	A4     = PaperSize{210 * Millimetre, 297 * Millimetre}
+	A5     = PaperSize{148 * Millimetre, 210 * Millimetre}
+	Letter = PaperSize{8.5 * Inch, 11 * Inch}
+	Legal  = PaperSize{8.5 * Inch, 14 * Inch}
+)
+
+// Landscape returns the paper size with the longer side horizontal.
+func (s PaperSize) Landscape() PaperSize {
+	if s.Width >= s.Height {
+		return s
+	}
+	return PaperSize{s.Height, s.Width}
+}
+
+// orientedFor returns the paper size in the orientation that matches the aspect ratio of an AspectLocked node
+func (s PaperSize) orientedFor(n Node) PaperSize {
+	locked, ok := n.(AspectLocked)
+	if !ok || !validRatio(locked.AspectRatio) {
+		return s
+	}
+	landscape := s.Landscape()
+	if locked.AspectRatio > 1 {
+		return landscape
+	}
+	return PaperSize{landscape.Height, landscape.Width}
+}
+
+// PDFOptions configures WritePDF. Zero values are replaced with the defaults listed below.
+type PDFOptions struct {
+	// PaperSize defaults to A4.
+	PaperSize PaperSize
+	// DPI is the resolution that the layout is calculated at. It determines the rounding of the layout and the
+	// meaning of BorderWidth, but the pictures are always embedded at their full resolution. Defaults to 300.
+	DPI int
+	// Margin is the empty space left around the composed image on every page.
+	Margin Length
+	// BorderColor and BorderWidth have the same meaning as the arguments of DrawWithBorder, with BorderWidth being
+	// in pixels at the given DPI. Borders are only drawn if BorderWidth is positive.
+	BorderColor color.Color
+	BorderWidth int
+	// MatchOrientation turns the paper of every page whose node is an AspectLocked node, such as the nodes composed
+	// by GridLayout, to landscape or to portrait to match the aspect ratio of the node.
+	MatchOrientation bool
+}
+
+const defaultPDFDPI = 300
+
+// WritePDF writes a PDF document with each of the nodes composed onto a separate page. The pictures are embedded at
+// their full source resolution and are cropped with clip paths using the same geometry as DrawWithBorder would use at
+// the configured DPI, and the backgrounds of AspectLocked and Canvas nodes are filled rectangles stacked with the
+// pictures in the order that DrawWithBorder paints them. Like WriteSVG, the document leaves out the filters of
+// Filtered nodes, the captions of Captioned nodes, the overlays of Watermark nodes and the frames of canvas items, and
+// it places the pictures of rotated canvas items as if they weren't rotated. Custom Node implementations are not
+// included in the document, see Placements.
+func WritePDF(w io.Writer, pages []Node, o PDFOptions) error {
+	if o.PaperSize == (PaperSize{}) {
+		o.PaperSize = A4
+	}
+	if o.DPI <= 0 {
+		o.DPI = defaultPDFDPI
+	}
+	if o.BorderWidth < 0 {
+		o.BorderWidth = 0
+	}
+
+	p := newPDFWriter(w)
+	p.writeHeader()
+	catalog, pageTree := p.alloc(), p.alloc()
+	images := make(map[image.Image]int)
+	var pageIDs []int
+	for _, n := range pages {
+		pageIDs = append(pageIDs, p.writePage(n, pageTree, o, images))
+	}
+
+	var kids bytes.Buffer
+	for _, id := range pageIDs {
+		fmt.Fprintf(&kids, "%d 0 R ", id)
+	}
+	p.writeObject(pageTree, fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count %d >>", kids.String(), len(pageIDs)))
+	p.writeObject(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pageTree))
+	p.writeTrailer(catalog)
+	return p.flush()
+}
+
+// Paginate splits the images into groups of at most perPage images and composes every group with the layout, so that
+// large image sets could be written into a multi-page document with WritePDF.
+func Paginate(l Layout, images []image.Image, perPage int) []Node {
+	if perPage <= 0 {
+		perPage = len(images)
+	}
+	var pages []Node
+	for len(images) > 0 {
+		count := perPage
+		if count > len(images) {
+			count = len(images)
+		}
+		pages = append(pages, l.Compose(images[:count]))
+		images = images[count:]
+	}
+	return pages
+}
+
+type pdfWriter struct {
+	w       *bufio.Writer
+	offset  int64
+	offsets []int64
+	err     error
+}
+
+func newPDFWriter(w io.Writer) *pdfWriter {
+	return &pdfWriter{w: bufio.NewWriter(w)}
+}
+
+func (p *pdfWriter) write(data []byte) {
+	if p.err != nil {
+		return
+	}
+	n, err := p.w.Write(data)
+	p.offset += int64(n)
+	p.err = err
+}
+
+func (p *pdfWriter) printf(format string, args ...interface{}) {
+	p.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+// alloc reserves an object number, so that objects could refer to objects that are written after them
+func (p *pdfWriter) alloc() int {
+	p.offsets = append(p.offsets, -1)
+	return len(p.offsets)
+}
+
+func (p *pdfWriter) writeHeader() {
+	// The comment with high-bit characters marks the file as binary for any tools that care
+	p.write([]byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"))
+}
+
+func (p *pdfWriter) writeObject(id int, dict string) {
+	p.offsets[id-1] = p.offset
+	p.printf("%d 0 obj\n%s\nendobj\n", id, dict)
+}
+
+func (p *pdfWriter) writeStream(id int, dict string, data []byte) {
+	p.offsets[id-1] = p.offset
+	p.printf("%d 0 obj\n<< %s /Length %d >>\nstream\n", id, dict, len(data))
+	p.write(data)
+	p.printf("\nendstream\nendobj\n")
+}
+
+func (p *pdfWriter) writeTrailer(root int) {
+	xref := p.offset
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, root, xref)
+}
+
+func (p *pdfWriter) flush() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+func (p *pdfWriter) writePage(n Node, pageTree int, o PDFOptions, images map[image.Image]int) int {
+	if o.MatchOrientation {
+		o.PaperSize = o.PaperSize.orientedFor(n)
+	}
+	// PDF coordinates are in points, i.e. in the same units as Length
+	scale := float64(Inch) / float64(o.DPI)
+	margin, pageHeight := float64(o.Margin), float64(o.PaperSize.Height)
+	width := (o.PaperSize.Width - 2*o.Margin).Pixels(float64(o.DPI))
+	height := (o.PaperSize.Height - 2*o.Margin).Pixels(float64(o.DPI))
+
+	// PDF coordinates grow upwards from the bottom left corner of the page, while image coordinates grow downwards
+	// from the top left corner of the composed image
+	toPage := func(x, y int) (float64, float64) {
+		return margin + float64(x)*scale, pageHeight - margin - float64(y)*scale
+	}
+
+	var content bytes.Buffer
+	fill := func(rect image.Rectangle, c color.Color) {
+		r, g, b := pdfColor(c)
+		x, y := toPage(rect.Min.X, rect.Max.Y)
+		fmt.Fprintf(&content, "%.4f %.4f %.4f rg %.4f %.4f %.4f %.4f re f\n",
+			r, g, b, x, y, float64(rect.Dx())*scale, float64(rect.Dy())*scale)
+	}
+	if o.BorderWidth > 0 && o.BorderColor != nil {
+		fill(image.Rect(0, 0, width, height), resolveBorderColor(n, o.BorderColor))
+	}
+
+	var resources bytes.Buffer
+	used := make(map[int]bool)
+	for _, area := range paintedAreas(n, width, height, o.BorderWidth) {
+		if area.background != nil {
+			if !area.rect.Empty() {
+				fill(area.rect, area.background)
+			}
+			continue
+		}
+		placement := area.placement
+		if placement.Rect.Empty() || placement.Crop.Empty() {
+			continue
+		}
+		id := p.imageObject(placement.Picture, images)
+		if !used[id] {
+			used[id] = true
+			fmt.Fprintf(&resources, "/Im%d %d 0 R ", id, id)
+		}
+
+		cellX, cellY := toPage(placement.Rect.Min.X, placement.Rect.Max.Y)
+		cellWidth, cellHeight := float64(placement.Rect.Dx())*scale, float64(placement.Rect.Dy())*scale
+
+		// Scale the whole picture so that the crop rectangle would exactly cover the cell
+		bounds := placement.Picture.Bounds()
+		sx := cellWidth / float64(placement.Crop.Dx())
+		sy := cellHeight / float64(placement.Crop.Dy())
+		imageWidth, imageHeight := float64(bounds.Dx())*sx, float64(bounds.Dy())*sy
+		imageX := cellX - float64(placement.Crop.Min.X-bounds.Min.X)*sx
+		imageTop := cellY + cellHeight + float64(placement.Crop.Min.Y-bounds.Min.Y)*sy
+		imageY := imageTop - imageHeight
+
+		fmt.Fprintf(&content, "q %.4f %.4f %.4f %.4f re W n %.4f 0 0 %.4f %.4f %.4f cm /Im%d Do Q\n",
+			cellX, cellY, cellWidth, cellHeight, imageWidth, imageHeight, imageX, imageY, id)
+	}
+
+	contentID := p.alloc()
+	p.writeStream(contentID, "", content.Bytes())
+	pageID := p.alloc()
+	p.writeObject(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] "+
+		"/Resources << /XObject << %s>> >> /Contents %d 0 R >>",
+		pageTree, o.PaperSize.Width, o.PaperSize.Height, resources.String(), contentID))
+	return pageID
+}
+
+// imageObject writes the picture as an image XObject, unless it has already been written for a previous placement,
+// and returns its object number
+func (p *pdfWriter) imageObject(picture image.Image, images map[image.Image]int) int {
+	// Only pictures with comparable dynamic types can be used as map keys
+	comparable := reflect.TypeOf(picture).Comparable()
+	if comparable {
+		if id, ok := images[picture]; ok {
+			return id
+		}
+	}
+
+	bounds := picture.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	pixels, err := decodePixels(picture)
+	if err != nil && p.err == nil {
+		p.err = err
+	} else if err == nil {
+		draw.Draw(rgba, rgba.Bounds(), pixels, pixels.Bounds().Min, draw.Src)
+	}
+
+	rgb := make([]byte, 0, 3*bounds.Dx()*bounds.Dy())
+	alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+	opaque := true
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		r, g, b, a := rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], rgba.Pix[i+3]
+		if a != 0xff {
+			opaque = false
+			if a != 0 {
+				// PDF expects colors that haven't been premultiplied by alpha
+				r = uint8(uint16(r) * 0xff / uint16(a))
+				g = uint8(uint16(g) * 0xff / uint16(a))
+				b = uint8(uint16(b) * 0xff / uint16(a))
+			}
+		}
+		rgb = append(rgb, r, g, b)
+		alpha = append(alpha, a)
+	}
+
+	smask := ""
+	if !opaque {
+		maskID := p.alloc()
+		p.writeStream(maskID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d "+
+			"/ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode", bounds.Dx(), bounds.Dy()), deflate(alpha))
+		smask = fmt.Sprintf(" /SMask %d 0 R", maskID)
+	}
+	id := p.alloc()
+	p.writeStream(id, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d "+
+		"/ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode%s", bounds.Dx(), bounds.Dy(), smask), deflate(rgb))
+
+	if comparable {
+		images[picture] = id
+	}
+	return id
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func pdfColor(c color.Color) (float64, float64, float64) {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return float64(nrgba.R) / 0xff, float64(nrgba.G) / 0xff, float64(nrgba.B) / 0xff
+}
@@ -0,0 +1,139 @@
+package picasso_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WritePDF", func() {
+	var (
+		images []image.Image
+		pdf    []byte
+	)
+
+	BeforeEach(func() {
+		images = []image.Image{
+			uniformImage(300, 200, color.RGBA{0xff, 0x00, 0x00, 0xff}),
+			uniformImage(100, 200, color.RGBA{0x00, 0xff, 0x00, 0xff}),
+			uniformImage(200, 200, color.RGBA{0x00, 0x00, 0xff, 0x80}),
+		}
+	})
+
+	writePDF := func(pages []Node, o PDFOptions) {
+		var buf bytes.Buffer
+		Expect(WritePDF(&buf, pages, o)).To(Succeed())
+		pdf = buf.Bytes()
+	}
+
+	It("writes a structurally valid document", func() {
+		writePDF([]Node{Picture{images[0]}}, PDFOptions{})
+		Expect(pdf).To(HavePrefix("%PDF-1.4\n"))
+		Expect(pdf).To(HaveSuffix("%%EOF\n"))
+
+		startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+		Expect(startxref).NotTo(BeNil())
+		xref, err := strconv.Atoi(string(startxref[1]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pdf[xref:]).To(HavePrefix("xref\n"))
+
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+		Expect(entries).NotTo(BeEmpty())
+		for i, entry := range entries {
+			offset, err := strconv.Atoi(string(entry[1]))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdf[offset:]).To(HavePrefix(fmt.Sprintf("%d 0 obj\n", i+1)))
+		}
+	})
+
+	It("turns the pages to match the orientation of the grid layout", func() {
+		landscape := uniformImage(300, 200, color.RGBA{0xff, 0x00, 0x00, 0xff})
+		pages := Paginate(GridLayout(), []image.Image{landscape, landscape, landscape}, 2)
+		Expect(pages).To(HaveLen(2))
+		writePDF(pages, PDFOptions{MatchOrientation: true})
+		mediaBoxes := regexp.MustCompile(`/MediaBox \[0 0 ([\d.]+) ([\d.]+)\]`).FindAllStringSubmatch(string(pdf), -1)
+		Expect(mediaBoxes).To(HaveLen(2))
+		// Two landscape pictures one atop the other make a portrait grid and a single one a landscape grid
+		Expect(mediaBoxes[0][1:]).To(Equal([]string{"595.28", "841.89"}))
+		Expect(mediaBoxes[1][1:]).To(Equal([]string{"841.89", "595.28"}))
+	})
+
+	It("lays the grid layout out with the aspect ratio of A-series paper", func() {
+		landscape := uniformImage(300, 200, color.RGBA{0xff, 0x00, 0x00, 0xff})
+		node := GridLayout().Compose([]image.Image{landscape, landscape})
+		// An A4 page at 300 DPI
+		placements := Placements(node, 2480, 3508, 0)
+		Expect(placements).To(HaveLen(2))
+		Expect(placements[0].Rect).To(Equal(image.Rect(0, 0, 2480, 1754)))
+		Expect(placements[1].Rect).To(Equal(image.Rect(0, 1754, 2480, 3507)))
+	})
+
+	It("uses the paper size", func() {
+		writePDF([]Node{Picture{images[0]}}, PDFOptions{PaperSize: Letter.Landscape()})
+		Expect(string(pdf)).To(ContainSubstring("/MediaBox [0 0 792.00 612.00]"))
+	})
+
+	It("embeds every picture once at its full resolution", func() {
+		node := GoldenSpiralLayout().Compose([]image.Image{images[0], images[1], images[0]})
+		writePDF([]Node{node}, PDFOptions{})
+		Expect(regexp.MustCompile(`/Subtype /Image /Width 300 /Height 200 /ColorSpace /DeviceRGB`).FindAll(pdf, -1)).To(HaveLen(1))
+		Expect(regexp.MustCompile(`/Subtype /Image /Width 100 /Height 200 /ColorSpace /DeviceRGB`).FindAll(pdf, -1)).To(HaveLen(1))
+		Expect(regexp.MustCompile(` Do Q\n`).FindAll(pdf, -1)).To(HaveLen(3))
+	})
+
+	It("adds a soft mask to transparent pictures", func() {
+		writePDF([]Node{Picture{images[2]}}, PDFOptions{})
+		Expect(string(pdf)).To(ContainSubstring("/ColorSpace /DeviceGray"))
+		Expect(string(pdf)).To(MatchRegexp(`/ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /SMask \d+ 0 R`))
+	})
+
+	It("clips the pictures to their cells", func() {
+		writePDF([]Node{Picture{images[0]}}, PDFOptions{
+			PaperSize: PaperSize{144, 144},
+			DPI:       72,
+			Margin:    12,
+		})
+		// The 300x200 picture is cropped to its central 200x200 part, which is scaled to the 120x120 cell
+		Expect(string(pdf)).To(ContainSubstring("q 12.0000 12.0000 120.0000 120.0000 re W n " +
+			"180.0000 0 0 120.0000 -18.0000 12.0000 cm"))
+	})
+
+	It("draws the borders", func() {
+		writePDF([]Node{Picture{images[0]}}, PDFOptions{
+			PaperSize:   PaperSize{144, 144},
+			DPI:         72,
+			BorderColor: color.RGBA{0xff, 0xff, 0xff, 0xff},
+			BorderWidth: 4,
+		})
+		Expect(string(pdf)).To(ContainSubstring("1.0000 1.0000 1.0000 rg 0.0000 0.0000 144.0000 144.0000 re f"))
+		Expect(string(pdf)).To(ContainSubstring("q 4.0000 4.0000 136.0000 136.0000 re W n"))
+	})
+
+	It("draws the backgrounds of the nodes under the pictures within them", func() {
+		node := AspectLocked{Node: Picture{images[0]}, AspectRatio: 1, Background: color.RGBA{0xff, 0xff, 0x00, 0xff}}
+		writePDF([]Node{node}, PDFOptions{PaperSize: PaperSize{144, 72}, DPI: 72})
+		Expect(string(pdf)).To(MatchRegexp(`1.0000 1.0000 0.0000 rg 0.0000 0.0000 144.0000 72.0000 re f\n` +
+			`q 36.0000 0.0000 72.0000 72.0000 re W n `))
+	})
+
+	Describe("Paginate", func() {
+		It("composes a page for every group of images", func() {
+			pages := Paginate(TopHeavyLayout(), append(images, images...), 4)
+			Expect(pages).To(HaveLen(2))
+			Expect(Placements(pages[0], 100, 100, 0)).To(HaveLen(4))
+			Expect(Placements(pages[1], 100, 100, 0)).To(HaveLen(2))
+
+			writePDF(pages, PDFOptions{})
+			Expect(string(pdf)).To(ContainSubstring("/Count 2"))
+			Expect(regexp.MustCompile(`/Type /Page /Parent`).FindAll(pdf, -1)).To(HaveLen(2))
+		})
+	})
+})
@@ -17,18 +17,38 @@ type Picture struct {
 	Picture image.Image
 }
 
+// Draw draws the picture resized to fill the given size. Sources that can't be decoded are drawn as transparent
+// areas, use Renderer.OnDecodeError to find out about them.
 func (n Picture) Draw(width, height int) image.Image {
+	m, _ := n.draw(width, height, FinalQuality)
+	return m
+}
+
+// draw resizes the picture to fill the given size with the resampling that the quality calls for. If the pixels of a
+// Source can't be decoded, it returns the error along with an empty image of the right size.
+func (n Picture) draw(width, height int, q Quality) (image.Image, error) {
 	g := gift.New(
-		gift.ResizeToFill(width, height, gift.LanczosResampling, gift.CenterAnchor),
+		gift.ResizeToFill(width, height, q.resampling(), gift.CenterAnchor),
 	)
 	dst := image.NewRGBA(g.Bounds(n.Picture.Bounds()))
-	g.Draw(dst, n.Picture)
-	return dst
+	picture, err := decodePixels(n.Picture)
+	if err != nil {
+		return dst, err
+	}
+	if q == DraftQuality {
+		picture = preShrink(picture, n.cropRect(width, height).Dx(), width)
+	}
+	g.Draw(dst, picture)
+	return dst, nil
 }
 
 func (n Picture) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	borderColor = resolveBorderColor(n, borderColor)
+	if m, ok := drawWithFill(n, width, height, borderColor, borderWidth); ok {
+		return m
+	}
 	fullRect := image.Rect(0, 0, width, height)
-	inBorderRect := image.Rect(borderWidth, borderWidth, width-borderWidth, height-borderWidth)
+	inBorderRect := insetRect(fullRect, borderWidth)
 
 	dst := image.NewRGBA(fullRect)
 
@@ -41,39 +61,61 @@ func (n Picture) DrawWithBorder(width, height int, borderColor color.Color, bord
 	return dst
 }
 
+// cropRect returns the part of the picture, in the picture's own coordinates, that remains visible after it has been
+// resized to fill an area of the given size. It mirrors what gift.ResizeToFill does with the gift.CenterAnchor.
+func (n Picture) cropRect(width, height int) image.Rectangle {
+	bounds := n.Picture.Bounds()
+	if width <= 0 || height <= 0 || bounds.Empty() {
+		return image.Rectangle{}
+	}
+	wratio := float64(bounds.Dx()) / float64(width)
+	hratio := float64(bounds.Dy()) / float64(height)
+	if wratio < hratio {
+		cropHeight := int(float64(height)*wratio + 0.5)
+		y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+		return image.Rect(bounds.Min.X, y, bounds.Max.X, y+cropHeight)
+	}
+	cropWidth := int(float64(width)*hratio + 0.5)
+	x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+	return image.Rect(x, bounds.Min.Y, x+cropWidth, bounds.Max.Y)
+}
+
+func insetRect(r image.Rectangle, n int) image.Rectangle {
+	return image.Rect(r.Min.X+n, r.Min.Y+n, r.Max.X-n, r.Max.Y-n)
+}
+
 type VerticalSplit struct {
 	Left  Node
 	Right Node
 	Ratio float32
+	// LeftSize and RightSize constrain the widths of the children. A Fixed size overrides the Ratio, while the Min and
+	// Max sizes clamp the widths that the Ratio gives the children.
+	LeftSize  Constraint
+	RightSize Constraint
 }
 
 func (n VerticalSplit) Draw(width, height int) image.Image {
-	rightWidth := n.rightWidth(width)
-	leftWidth := width - rightWidth
+	leftRect, rightRect := n.childRects(width, height, 0)
 
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 
-	leftImage := n.Left.Draw(leftWidth, height)
-	leftRect := image.Rect(0, 0, leftWidth, height)
+	leftImage := n.Left.Draw(leftRect.Dx(), leftRect.Dy())
 	draw.Draw(dst, leftRect, leftImage, image.ZP, draw.Over)
 
-	rightImage := n.Right.Draw(rightWidth, height)
-	rightRect := image.Rect(leftWidth, 0, width, height)
+	rightImage := n.Right.Draw(rightRect.Dx(), rightRect.Dy())
 	draw.Draw(dst, rightRect, rightImage, image.ZP, draw.Over)
 
 	return dst
 }
 
 func (n VerticalSplit) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
-	// + borderWidth, because we basically draw both sides with their full borders, but then make the right border of
-	// the left image and the left border of the right image overlap
-	rightWithBorderWidth := n.rightWidth(width + borderWidth)
-	leftWithBorderWidth := (width + borderWidth) - rightWithBorderWidth
-	fullRect := image.Rect(0, 0, width, height)
-	leftWithBorderRect := image.Rect(0, 0, leftWithBorderWidth, height)
-	rightWithBorderRect := image.Rect(leftWithBorderWidth-borderWidth, 0, width, height)
+	borderColor = resolveBorderColor(n, borderColor)
+	if m, ok := drawWithFill(n, width, height, borderColor, borderWidth); ok {
+		return m
+	}
+	leftWithBorderRect, rightWithBorderRect := n.childRects(width, height, borderWidth)
 
-	dst := image.NewRGBA(fullRect)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 
 	leftWithBorderImage := n.Left.DrawWithBorder(leftWithBorderRect.Dx(), leftWithBorderRect.Dy(), borderColor, borderWidth)
 	draw.Draw(dst, leftWithBorderRect, leftWithBorderImage, image.ZP, draw.Over)
@@ -84,6 +126,42 @@ func (n VerticalSplit) DrawWithBorder(width, height int, borderColor color.Color
 	return dst
 }
 
+// childRects returns the rectangles that the left and the right nodes will be drawn into. If borderWidth is non-zero
+// then the rectangles include the borders of the child nodes.
+func (n VerticalSplit) childRects(width, height, borderWidth int) (image.Rectangle, image.Rectangle) {
+	// + borderWidth, because we basically draw both sides with their full borders, but then make the right border of
+	// the left image and the left border of the right image overlap
+	desiredLeftWidth := n.desiredLeftWidth(width, height, borderWidth)
+	leftWithBorderWidth := solveSplit(width+borderWidth, desiredLeftWidth, n.LeftSize, n.RightSize, borderWidth)
+	leftWithBorderRect := image.Rect(0, 0, leftWithBorderWidth, height)
+	rightWithBorderRect := image.Rect(leftWithBorderWidth-borderWidth, 0, width, height)
+	return leftWithBorderRect, rightWithBorderRect
+}
+
+// desiredLeftWidth returns the width of the left child, including its borders, that the ratio of the split or the
+// aspect ratios that the children are locked to call for, see AspectLocked
+func (n VerticalSplit) desiredLeftWidth(width, height, borderWidth int) int {
+	total := width + borderWidth
+	byRatio := total - n.rightWidth(total)
+	leftRatio, leftLocked := lockedAspectRatio(n.Left)
+	rightRatio, rightLocked := lockedAspectRatio(n.Right)
+	contentHeight := float32(height - 2*borderWidth)
+	desired := byRatio
+	switch {
+	case leftLocked && rightLocked:
+		// The widths of the children are proportional to their aspect ratios
+		desired = total - VerticalSplit{Ratio: leftRatio / rightRatio}.rightWidth(total)
+	case leftLocked:
+		desired = int(contentHeight*leftRatio+0.5) + 2*borderWidth
+	case rightLocked:
+		desired = total - (int(contentHeight*rightRatio+0.5) + 2*borderWidth)
+	}
+	if !leavesRoom(total, desired, borderWidth) {
+		return byRatio
+	}
+	return desired
+}
+
 func (n VerticalSplit) rightWidth(width int) int {
 	// Go doesn't have a simple round function and the rounding direction doesn't really matter here,
 	// so we'll just coerce the result to an int which discards the fraction.
@@ -94,35 +172,34 @@ type HorizontalSplit struct {
 	Top    Node
 	Bottom Node
 	Ratio  float32
+	// TopSize and BottomSize constrain the heights of the children. A Fixed size overrides the Ratio, while the Min
+	// and Max sizes clamp the heights that the Ratio gives the children.
+	TopSize    Constraint
+	BottomSize Constraint
 }
 
 func (n HorizontalSplit) Draw(width, height int) image.Image {
-	bottomHeight := n.bottomHeight(height)
-	topHeight := height - bottomHeight
+	topRect, bottomRect := n.childRects(width, height, 0)
 
 	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 
-	topImage := n.Top.Draw(width, topHeight)
-	topRect := image.Rect(0, 0, width, topHeight)
+	topImage := n.Top.Draw(topRect.Dx(), topRect.Dy())
 	draw.Draw(dst, topRect, topImage, image.ZP, draw.Over)
 
-	bottomImage := n.Bottom.Draw(width, bottomHeight)
-	bottomRect := image.Rect(0, topHeight, width, height)
+	bottomImage := n.Bottom.Draw(bottomRect.Dx(), bottomRect.Dy())
 	draw.Draw(dst, bottomRect, bottomImage, image.ZP, draw.Over)
 
 	return dst
 }
 
 func (n HorizontalSplit) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
-	// + borderWidth, because we basically draw both sides with their full borders, but then make the bottom border of
-	// the top image and the top border of the bottom image overlap
-	bottomWithBorderHeight := n.bottomHeight(height + borderWidth)
-	topWithBorderHeight := (height + borderWidth) - bottomWithBorderHeight
-	fullRect := image.Rect(0, 0, width, height)
-	topWithBorderRect := image.Rect(0, 0, width, topWithBorderHeight)
-	bottomWithBorderRect := image.Rect(0, topWithBorderHeight-borderWidth, width, height)
+	borderColor = resolveBorderColor(n, borderColor)
+	if m, ok := drawWithFill(n, width, height, borderColor, borderWidth); ok {
+		return m
+	}
+	topWithBorderRect, bottomWithBorderRect := n.childRects(width, height, borderWidth)
 
-	dst := image.NewRGBA(fullRect)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
 
 	topWithBorderImage := n.Top.DrawWithBorder(topWithBorderRect.Dx(), topWithBorderRect.Dy(), borderColor, borderWidth)
 	draw.Draw(dst, topWithBorderRect, topWithBorderImage, image.ZP, draw.Over)
@@ -133,6 +210,42 @@ func (n HorizontalSplit) DrawWithBorder(width, height int, borderColor color.Col
 	return dst
 }
 
+// childRects returns the rectangles that the top and the bottom nodes will be drawn into. If borderWidth is non-zero
+// then the rectangles include the borders of the child nodes.
+func (n HorizontalSplit) childRects(width, height, borderWidth int) (image.Rectangle, image.Rectangle) {
+	// + borderWidth, because we basically draw both sides with their full borders, but then make the bottom border of
+	// the top image and the top border of the bottom image overlap
+	desiredTopHeight := n.desiredTopHeight(width, height, borderWidth)
+	topWithBorderHeight := solveSplit(height+borderWidth, desiredTopHeight, n.TopSize, n.BottomSize, borderWidth)
+	topWithBorderRect := image.Rect(0, 0, width, topWithBorderHeight)
+	bottomWithBorderRect := image.Rect(0, topWithBorderHeight-borderWidth, width, height)
+	return topWithBorderRect, bottomWithBorderRect
+}
+
+// desiredTopHeight returns the height of the top child, including its borders, that the ratio of the split or the
+// aspect ratios that the children are locked to call for, see AspectLocked
+func (n HorizontalSplit) desiredTopHeight(width, height, borderWidth int) int {
+	total := height + borderWidth
+	byRatio := total - n.bottomHeight(total)
+	topRatio, topLocked := lockedAspectRatio(n.Top)
+	bottomRatio, bottomLocked := lockedAspectRatio(n.Bottom)
+	contentWidth := float32(width - 2*borderWidth)
+	desired := byRatio
+	switch {
+	case topLocked && bottomLocked:
+		// The heights of the children are inversely proportional to their aspect ratios
+		desired = total - HorizontalSplit{Ratio: bottomRatio / topRatio}.bottomHeight(total)
+	case topLocked:
+		desired = int(contentWidth/topRatio+0.5) + 2*borderWidth
+	case bottomLocked:
+		desired = total - (int(contentWidth/bottomRatio+0.5) + 2*borderWidth)
+	}
+	if !leavesRoom(total, desired, borderWidth) {
+		return byRatio
+	}
+	return desired
+}
+
 func (n HorizontalSplit) bottomHeight(height int) int {
 	// Go doesn't have a simple round function and the rounding direction doesn't really matter here,
 	// so we'll just coerce the result to an int which discards the fraction.
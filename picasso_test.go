@@ -3,6 +3,7 @@ package picasso_test
 import (
 	"image"
 	"image/color"
+	"image/draw"
 	_ "image/jpeg"
 	"image/png"
 	"os"
@@ -72,6 +73,21 @@ func (i TestImage) write(data image.Image) {
 	png.Encode(outfile, data)
 }
 
+func uniformImage(width, height int, c color.Color) image.Image {
+	i := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(i, i.Bounds(), image.NewUniform(c), image.ZP, draw.Src)
+	return i
+}
+
+// placementRects returns the rectangles that the pictures of the tree are placed into, see Placements
+func placementRects(n Node, width, height, borderWidth int) []image.Rectangle {
+	var rects []image.Rectangle
+	for _, p := range Placements(n, width, height, borderWidth) {
+		rects = append(rects, p.Rect)
+	}
+	return rects
+}
+
 var _ = Describe("Picasso", func() {
 	ExpectToEqualTestImage := func(i image.Image, testImage TestImage) {
 		composed := testImage.read()
@@ -0,0 +1,206 @@
+package picassohttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/deiwin/picasso"
+
+	// Register the formats that are most likely to be found behind an image URL
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Fetcher resolves the image URLs of a JSON request into decoded images. Implementations should apply the EXIF
+// orientation of the images, see picasso.DecodeOriented.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (image.Image, error)
+}
+
+// FetcherFunc allows using an ordinary function as a Fetcher.
+type FetcherFunc func(ctx context.Context, url string) (image.Image, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, url string) (image.Image, error) {
+	return f(ctx, url)
+}
+
+// FetcherOptions configures HTTPFetcher. Zero values are replaced with the defaults listed below.
+//
+// Any client that can reach the handler can make the fetcher send GET requests to any URL it likes. The default
+// client therefore refuses to connect to loopback, private, link-local and other non-public addresses, so that the
+// handler couldn't be used to reach services that are only meant to be accessed from within the same network. Only
+// http and https URLs are fetched at all.
+type FetcherOptions struct {
+	// Client downloads the images. Defaults to a client that times out after 10s and only connects to public
+	// addresses. A custom client has to restrict the addresses it connects to itself if that's needed. The URLs of
+	// redirects are checked against the schemes and AllowedHosts with any client.
+	Client *http.Client
+	// MaxBytes limits the size of any single response. Defaults to 10MB.
+	MaxBytes int64
+	// MaxPixels limits the dimensions of any single image, so that a small file that claims huge dimensions couldn't
+	// exhaust the memory when decoded. Defaults to 50 megapixels.
+	MaxPixels int64
+	// AllowedHosts, if set, lists the only hosts, e.g. "images.example.com", that images are fetched from.
+	AllowedHosts []string
+}
+
+const (
+	defaultFetchTimeout = 10 * time.Second
+	defaultMaxPixels    = 50 * 1000 * 1000
+)
+
+// maxRedirects is the number of redirects that the default policy of http.Client follows
+const maxRedirects = 10
+
+// HTTPFetcher returns a Fetcher that downloads images over http or https, see FetcherOptions. The scheme and the host
+// of every redirect are checked just like those of the original URL, whether the client is a custom one or not.
+func HTTPFetcher(o FetcherOptions) Fetcher {
+	if o.Client == nil {
+		o.Client = publicClient()
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = defaultMaxImageBytes
+	}
+	if o.MaxPixels <= 0 {
+		o.MaxPixels = defaultMaxPixels
+	}
+	f := httpFetcher{o}
+	// Copy the client, so that the redirect policy of the caller's client wouldn't be changed
+	client := *o.Client
+	client.CheckRedirect = f.checkRedirect(o.Client.CheckRedirect)
+	f.options.Client = &client
+	return f
+}
+
+type httpFetcher struct {
+	options FetcherOptions
+}
+
+func (f httpFetcher) Fetch(ctx context.Context, rawURL string) (image.Image, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, fmt.Errorf("fetching %s: %v", rawURL, err)
+	}
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.options.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", rawURL, resp.Status)
+	}
+	// Read one byte over the limit so that we'd be able to tell a truncated image apart from one that just fits
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, f.options.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %v", rawURL, err)
+	} else if int64(len(data)) > f.options.MaxBytes {
+		return nil, fmt.Errorf("fetching %s: image exceeds %d bytes", rawURL, f.options.MaxBytes)
+	}
+	img, err := decodeImage(bytes.NewReader(data), f.options.MaxPixels)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %v", rawURL, err)
+	}
+	return img, nil
+}
+
+// checkURL reports an error if the URL isn't one that the fetcher is allowed to fetch
+func (f httpFetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if !f.allowed(u.Hostname()) {
+		return fmt.Errorf("host %q is not allowed", u.Hostname())
+	}
+	return nil
+}
+
+// checkRedirect returns a redirect policy that checks the URL of every redirect before applying the given policy, or
+// the default policy of http.Client if it's nil
+func (f httpFetcher) checkRedirect(next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if err := f.checkURL(req.URL); err != nil {
+			return fmt.Errorf("redirected to %s: %v", req.URL, err)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+}
+
+func (f httpFetcher) allowed(host string) bool {
+	if len(f.options.AllowedHosts) == 0 {
+		return true
+	}
+	for _, allowed := range f.options.AllowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicClient creates a client that refuses to connect to anything but public addresses. The addresses are checked
+// after they have been resolved, so that a host name that resolves to a private address couldn't get around it.
+func publicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: defaultFetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport, Timeout: defaultFetchTimeout}
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// decodeImage checks the dimensions of the image before decoding it, so that a small file that claims huge
+// dimensions would be rejected before any memory is allocated for its pixels
+func decodeImage(r io.ReadSeeker, maxPixels int64) (image.Image, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds %d pixels", config.Width, config.Height, maxPixels)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := picasso.DecodeOriented(r)
+	return img, err
+}
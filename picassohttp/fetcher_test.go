@@ -0,0 +1,88 @@
+package picassohttp_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/deiwin/picasso/picassohttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPFetcher", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			png.Encode(w, uniformImage(40, 30, color.RGBA{0xff, 0x00, 0x00, 0xff}))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	fetch := func(o FetcherOptions, url string) (image.Image, error) {
+		return HTTPFetcher(o).Fetch(context.Background(), url)
+	}
+
+	It("fetches images with a custom client", func() {
+		i, err := fetch(FetcherOptions{Client: server.Client()}, server.URL+"/red.png")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 40, 30)))
+	})
+
+	It("refuses to connect to non-public addresses by default", func() {
+		_, err := fetch(FetcherOptions{}, server.URL+"/red.png")
+		Expect(err).To(MatchError(ContainSubstring("refusing to connect to non-public address 127.0.0.1")))
+	})
+
+	It("only fetches http and https URLs", func() {
+		_, err := fetch(FetcherOptions{Client: server.Client()}, "file:///etc/passwd")
+		Expect(err).To(MatchError(ContainSubstring(`unsupported scheme "file"`)))
+	})
+
+	It("only fetches from the allowed hosts", func() {
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		o := FetcherOptions{Client: server.Client(), AllowedHosts: []string{"images.example.com"}}
+		_, err = fetch(o, server.URL+"/red.png")
+		Expect(err).To(MatchError(ContainSubstring(`host "127.0.0.1" is not allowed`)))
+		o.AllowedHosts = append(o.AllowedHosts, u.Hostname())
+		_, err = fetch(o, server.URL+"/red.png")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("checks the hosts of redirects", func() {
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://localhost:"+u.Port()+"/red.png", http.StatusFound)
+		}))
+		defer redirecting.Close()
+		r, err := url.Parse(redirecting.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		client := redirecting.Client()
+		o := FetcherOptions{Client: client, AllowedHosts: []string{r.Hostname()}}
+		_, err = fetch(o, redirecting.URL+"/red.png")
+		Expect(err).To(MatchError(ContainSubstring(`host "localhost" is not allowed`)))
+		Expect(client.CheckRedirect).To(BeNil())
+		o.AllowedHosts = append(o.AllowedHosts, "localhost")
+		_, err = fetch(o, redirecting.URL+"/red.png")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects images over the limits", func() {
+		_, err := fetch(FetcherOptions{Client: server.Client(), MaxPixels: 40*30 - 1}, server.URL+"/red.png")
+		Expect(err).To(MatchError(ContainSubstring("image of 40x30 pixels exceeds 1199 pixels")))
+		_, err = fetch(FetcherOptions{Client: server.Client(), MaxBytes: 10}, server.URL+"/red.png")
+		Expect(err).To(MatchError(ContainSubstring("image exceeds 10 bytes")))
+	})
+})
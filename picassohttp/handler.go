@@ -0,0 +1,403 @@
+// Package picassohttp exposes the picasso layouts as an http.Handler. Clients either upload the images to be composed
+// as a multipart form or send a JSON body listing image URLs, which are resolved through a pluggable Fetcher. The
+// composed image is encoded and streamed back in the response.
+package picassohttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deiwin/picasso"
+)
+
+// Config configures the handler returned by NewHandler. Zero values are replaced with the defaults listed below.
+type Config struct {
+	// Fetcher resolves image URLs in JSON requests. Defaults to an HTTPFetcher with MaxImageBytes and MaxImagePixels
+	// as its limits, see FetcherOptions for the hosts that it connects to.
+	Fetcher Fetcher
+	// MaxRequestBytes limits the size of the request body. Defaults to 32MB.
+	MaxRequestBytes int64
+	// MaxImageBytes limits the size of a single image fetched by the default Fetcher. Defaults to 10MB.
+	MaxImageBytes int64
+	// MaxImagePixels limits the dimensions of a single uploaded image or an image fetched by the default Fetcher.
+	// Defaults to 50 megapixels.
+	MaxImagePixels int64
+	// MaxImages limits the number of images in a single request. Defaults to 50.
+	MaxImages int
+	// MaxWidth and MaxHeight limit the dimensions of the composed image. Both default to 4096.
+	MaxWidth  int
+	MaxHeight int
+	// Timeout limits the time spent on fetching the images and composing the result. Defaults to 30s.
+	Timeout time.Duration
+	// MaxConcurrentRenders limits the number of images being composed at once. Composing an image can't be
+	// interrupted, so a render keeps running after its request has timed out and it counts against the limit until it
+	// finishes. Requests wait for a render to finish while the limit is reached and fail with 503 Service Unavailable
+	// if their Timeout passes first. Defaults to the number of CPUs.
+	MaxConcurrentRenders int
+	// ErrorLog logs the errors that can't be reported to the client anymore, because the response has already been
+	// started, such as failures to write the composed image. Defaults to the standard logger.
+	ErrorLog *log.Logger
+}
+
+const (
+	defaultMaxRequestBytes = 32 << 20
+	defaultMaxImageBytes   = 10 << 20
+	defaultMaxImages       = 50
+	defaultMaxDimension    = 4096
+	defaultTimeout         = 30 * time.Second
+
+	// multipartMemory is how much of a multipart body is kept in memory before spilling uploads to disk
+	multipartMemory = 8 << 20
+)
+
+// NewHandler creates a handler that composes the images of a POST request into a single image.
+//
+// A multipart/form-data request provides the images as files in the "images" field and the parameters as form
+// values. An application/json request provides the same parameters and a list of image URLs in the "images" field:
+//
+//	{"layout": "golden_spiral", "width": 600, "height": 600, "images": ["https://..."]}
+//
+// The supported parameters are "layout" (one of "top_heavy", "golden_spiral" or "grid"), "width", "height" (ignored
+// by the grid layout, which derives it from the width), "border_width", "border_color" (a hex color, e.g. "#afafaf",
+// or "auto" to derive it from the images) and "format" ("png", the default, or "jpeg").
+func NewHandler(c Config) http.Handler {
+	if c.MaxRequestBytes <= 0 {
+		c.MaxRequestBytes = defaultMaxRequestBytes
+	}
+	if c.MaxImageBytes <= 0 {
+		c.MaxImageBytes = defaultMaxImageBytes
+	}
+	if c.MaxImagePixels <= 0 {
+		c.MaxImagePixels = defaultMaxPixels
+	}
+	if c.MaxImages <= 0 {
+		c.MaxImages = defaultMaxImages
+	}
+	if c.MaxWidth <= 0 {
+		c.MaxWidth = defaultMaxDimension
+	}
+	if c.MaxHeight <= 0 {
+		c.MaxHeight = defaultMaxDimension
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.MaxConcurrentRenders <= 0 {
+		c.MaxConcurrentRenders = runtime.NumCPU()
+	}
+	if c.ErrorLog == nil {
+		c.ErrorLog = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	if c.Fetcher == nil {
+		c.Fetcher = HTTPFetcher(FetcherOptions{MaxBytes: c.MaxImageBytes, MaxPixels: c.MaxImagePixels})
+	}
+	return handler{
+		config:  c,
+		renders: make(chan struct{}, c.MaxConcurrentRenders),
+	}
+}
+
+type handler struct {
+	config Config
+	// renders holds a token for every render that's still running, including the ones that have been abandoned
+	renders chan struct{}
+}
+
+// params are the rendering parameters shared by both request formats
+type params struct {
+	Layout      string   `json:"layout"`
+	Width       int      `json:"width"`
+	Height      int      `json:"height"`
+	BorderWidth int      `json:"border_width"`
+	BorderColor string   `json:"border_color"`
+	Format      string   `json:"format"`
+	Images      []string `json:"images"`
+}
+
+// requestError is an error that should be reported back to the client with the given status
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e requestError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return requestError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.config.Timeout)
+	defer cancel()
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxRequestBytes)
+
+	p, images, err := h.readRequest(ctx, r)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	composed, err := h.render(ctx, p, images)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	if p.Format == "jpeg" {
+		w.Header().Set("Content-Type", "image/jpeg")
+		err = jpeg.Encode(w, composed, nil)
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+		err = png.Encode(w, composed)
+	}
+	if err != nil {
+		// The status has already been sent, so the client can only tell from the truncated body
+		h.config.ErrorLog.Printf("picassohttp: writing the composed image: %v", err)
+	}
+}
+
+func (h handler) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	var reqErr requestError
+	switch {
+	case errors.As(err, &reqErr):
+		http.Error(w, reqErr.msg, reqErr.status)
+	case ctx.Err() == context.DeadlineExceeded:
+		http.Error(w, "timed out", http.StatusGatewayTimeout)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h handler) readRequest(ctx context.Context, r *http.Request) (params, []image.Image, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return params{}, nil, requestError{http.StatusUnsupportedMediaType, "missing or invalid Content-Type"}
+	}
+	var p params
+	var images []image.Image
+	switch mediaType {
+	case "multipart/form-data":
+		p, images, err = h.readMultipart(r)
+	case "application/json":
+		p, images, err = h.readJSON(ctx, r)
+	default:
+		return params{}, nil, requestError{http.StatusUnsupportedMediaType, "unsupported Content-Type " + mediaType}
+	}
+	if err != nil {
+		return params{}, nil, err
+	}
+	if len(images) == 0 {
+		return params{}, nil, badRequest("no images provided")
+	}
+	return p, images, nil
+}
+
+func (h handler) readMultipart(r *http.Request) (params, []image.Image, error) {
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return params{}, nil, bodyError(err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var p params
+	var err error
+	p.Layout = r.FormValue("layout")
+	p.BorderColor = r.FormValue("border_color")
+	p.Format = r.FormValue("format")
+	if p.Width, err = intFormValue(r, "width"); err != nil {
+		return params{}, nil, err
+	}
+	if p.Height, err = intFormValue(r, "height"); err != nil {
+		return params{}, nil, err
+	}
+	if p.BorderWidth, err = intFormValue(r, "border_width"); err != nil {
+		return params{}, nil, err
+	}
+	if err := h.validate(p); err != nil {
+		return params{}, nil, err
+	}
+
+	files := r.MultipartForm.File["images"]
+	if len(files) > h.config.MaxImages {
+		return params{}, nil, badRequest("at most %d images are allowed", h.config.MaxImages)
+	}
+	images := make([]image.Image, len(files))
+	for i, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			return params{}, nil, err
+		}
+		images[i], err = decodeImage(file, h.config.MaxImagePixels)
+		file.Close()
+		if err != nil {
+			return params{}, nil, badRequest("decoding %s: %v", header.Filename, err)
+		}
+	}
+	return p, images, nil
+}
+
+func intFormValue(r *http.Request, key string) (int, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badRequest("%s must be an integer", key)
+	}
+	return i, nil
+}
+
+func (h handler) readJSON(ctx context.Context, r *http.Request) (params, []image.Image, error) {
+	var p params
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return params{}, nil, bodyError(err)
+	}
+	if len(p.Images) > h.config.MaxImages {
+		return params{}, nil, badRequest("at most %d images are allowed", h.config.MaxImages)
+	}
+	// Validated before fetching, so that invalid requests wouldn't make the handler send any requests of its own
+	if err := h.validate(p); err != nil {
+		return params{}, nil, err
+	}
+	images := make([]image.Image, len(p.Images))
+	for i, url := range p.Images {
+		img, err := h.config.Fetcher.Fetch(ctx, url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return params{}, nil, ctx.Err()
+			}
+			return params{}, nil, requestError{http.StatusBadGateway, err.Error()}
+		}
+		images[i] = img
+	}
+	return p, images, nil
+}
+
+// bodyError distinguishes bodies that were cut off by http.MaxBytesReader from ones that were simply malformed
+func bodyError(err error) error {
+	if strings.Contains(err.Error(), "request body too large") {
+		return requestError{http.StatusRequestEntityTooLarge, "request body too large"}
+	}
+	return badRequest("invalid request body: %v", err)
+}
+
+// validate checks the rendering parameters. It's called before the images are decoded or fetched, so that invalid
+// requests would be rejected before any of the expensive work is done.
+func (h handler) validate(p params) error {
+	// The smallest height that the composed image can have
+	height := p.Height
+	switch p.Layout {
+	case "top_heavy", "golden_spiral":
+		if p.Height <= 0 || p.Height > h.config.MaxHeight {
+			return badRequest("height must be between 1 and %d", h.config.MaxHeight)
+		}
+	case "grid":
+		// The grid layout will be in portrait if it can't be in landscape, so assume the worst
+		if float64(p.Width)*math.Sqrt2 > float64(h.config.MaxHeight) {
+			return badRequest("the composed height would exceed %d", h.config.MaxHeight)
+		}
+		height = int(float64(p.Width) / math.Sqrt2)
+	default:
+		return badRequest("unknown layout %q", p.Layout)
+	}
+	if p.Width <= 0 || p.Width > h.config.MaxWidth {
+		return badRequest("width must be between 1 and %d", h.config.MaxWidth)
+	}
+	if p.BorderWidth < 0 || 2*p.BorderWidth >= p.Width || 2*p.BorderWidth >= height {
+		return badRequest("invalid border_width")
+	}
+	switch p.Format {
+	case "", "png", "jpeg":
+	default:
+		return badRequest("unknown format %q", p.Format)
+	}
+	if _, err := parseColor(p.BorderColor); err != nil {
+		return err
+	}
+	return nil
+}
+
+// render composes the images in a separate goroutine so that the request could be abandoned once the deadline has
+// passed, even though the drawing itself can't be interrupted. The goroutine holds on to its place among the
+// concurrent renders until it finishes, so abandoned renders can't pile up beyond the limit.
+func (h handler) render(ctx context.Context, p params, images []image.Image) (image.Image, error) {
+	select {
+	case h.renders <- struct{}{}:
+	case <-ctx.Done():
+		return nil, requestError{http.StatusServiceUnavailable, "too many images are being composed, try again later"}
+	}
+	borderColor, _ := parseColor(p.BorderColor)
+	result := make(chan image.Image, 1)
+	go func() {
+		defer func() { <-h.renders }()
+		result <- draw(p, images, borderColor)
+	}()
+	select {
+	case composed := <-result:
+		return composed, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func draw(p params, images []image.Image, borderColor color.Color) image.Image {
+	if p.Layout == "grid" {
+		if p.BorderWidth > 0 {
+			return picasso.DrawGridLayoutWithBorder(images, p.Width, borderColor, p.BorderWidth)
+		}
+		return picasso.DrawGridLayout(images, p.Width)
+	}
+	var layout picasso.Layout
+	if p.Layout == "top_heavy" {
+		layout = picasso.TopHeavyLayout()
+	} else {
+		layout = picasso.GoldenSpiralLayout()
+	}
+	node := layout.Compose(images)
+	if p.BorderWidth > 0 {
+		return node.DrawWithBorder(p.Width, p.Height, borderColor, p.BorderWidth)
+	}
+	return node.Draw(p.Width, p.Height)
+}
+
+// parseColor parses colors in the #rrggbb or #rgb format. An empty string is interpreted as the gray used throughout
+// the picasso examples and "auto" as picasso.AutoBorderColor.
+func parseColor(s string) (color.Color, error) {
+	if s == "" {
+		return color.RGBA{0xaf, 0xaf, 0xaf, 0xff}, nil
+	} else if s == "auto" {
+		return picasso.AutoBorderColor, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, badRequest("invalid border_color %q", s)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, badRequest("invalid border_color %q", s)
+	}
+	return color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xff}, nil
+}
@@ -0,0 +1,298 @@
+package picassohttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	stdlog "log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/deiwin/picasso/picassohttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+func uniformImage(width, height int, c color.Color) image.Image {
+	i := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			i.Set(x, y, c)
+		}
+	}
+	return i
+}
+
+// failingWriter is a ResponseWriter whose connection is reset as soon as the body is written
+type failingWriter struct {
+	http.ResponseWriter
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		config   Config
+		fetched  map[string]image.Image
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		fetched = map[string]image.Image{
+			"http://example.com/red.png":  uniformImage(40, 30, color.RGBA{0xff, 0x00, 0x00, 0xff}),
+			"http://example.com/blue.png": uniformImage(30, 40, color.RGBA{0x00, 0x00, 0xff, 0xff}),
+		}
+		config = Config{
+			Fetcher: FetcherFunc(func(ctx context.Context, url string) (image.Image, error) {
+				if i, ok := fetched[url]; ok {
+					return i, nil
+				}
+				return nil, errors.New("not found")
+			}),
+		}
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(req *http.Request) {
+		NewHandler(config).ServeHTTP(recorder, req)
+	}
+
+	jsonRequest := func(body interface{}) *http.Request {
+		data, err := json.Marshal(body)
+		Expect(err).NotTo(HaveOccurred())
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	multipartRequest := func(fields map[string]string, images ...image.Image) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for key, value := range fields {
+			Expect(w.WriteField(key, value)).To(Succeed())
+		}
+		for _, i := range images {
+			part, err := w.CreateFormFile("images", "image.png")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(png.Encode(part, i)).To(Succeed())
+		}
+		Expect(w.Close()).To(Succeed())
+		req := httptest.NewRequest("POST", "/", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	decodeResponse := func() image.Image {
+		Expect(recorder.Code).To(Equal(http.StatusOK), recorder.Body.String())
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("image/png"))
+		i, err := png.Decode(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return i
+	}
+
+	Context("with a JSON body", func() {
+		It("composes the fetched images", func() {
+			serve(jsonRequest(map[string]interface{}{
+				"layout": "top_heavy",
+				"width":  100,
+				"height": 200,
+				"images": []string{"http://example.com/red.png", "http://example.com/blue.png"},
+			}))
+			i := decodeResponse()
+			Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 100, 200)))
+			Expect(i.At(50, 50)).To(Equal(color.RGBA{0xff, 0x00, 0x00, 0xff}))
+			Expect(i.At(50, 150)).To(Equal(color.RGBA{0x00, 0x00, 0xff, 0xff}))
+		})
+
+		It("draws borders with the requested color", func() {
+			serve(jsonRequest(map[string]interface{}{
+				"layout":       "golden_spiral",
+				"width":        100,
+				"height":       100,
+				"border_width": 4,
+				"border_color": "#00ff00",
+				"images":       []string{"http://example.com/red.png"},
+			}))
+			i := decodeResponse()
+			Expect(i.At(1, 1)).To(Equal(color.RGBA{0x00, 0xff, 0x00, 0xff}))
+			Expect(i.At(50, 50)).To(Equal(color.RGBA{0xff, 0x00, 0x00, 0xff}))
+		})
+
+		It("derives the border color from the images", func() {
+			serve(jsonRequest(map[string]interface{}{
+				"layout":       "top_heavy",
+				"width":        100,
+				"height":       100,
+				"border_width": 4,
+				"border_color": "auto",
+				"images":       []string{"http://example.com/red.png"},
+			}))
+			r, g, b, _ := decodeResponse().At(1, 1).RGBA()
+			Expect(r).To(BeNumerically(">", g))
+			Expect(g).To(Equal(b))
+		})
+
+		It("derives the height of the grid layout", func() {
+			serve(jsonRequest(map[string]interface{}{
+				"layout": "grid",
+				"width":  100,
+				"images": []string{"http://example.com/red.png"},
+			}))
+			Expect(decodeResponse().Bounds()).To(Equal(image.Rect(0, 0, 100, 70)))
+		})
+
+		It("reports images that couldn't be fetched", func() {
+			serve(jsonRequest(map[string]interface{}{
+				"layout": "grid",
+				"width":  100,
+				"images": []string{"http://example.com/missing.png"},
+			}))
+			Expect(recorder.Code).To(Equal(http.StatusBadGateway))
+		})
+
+		It("validates the parameters before fetching any images", func() {
+			config.Fetcher = FetcherFunc(func(ctx context.Context, url string) (image.Image, error) {
+				Fail("fetched " + url)
+				return nil, nil
+			})
+			serve(jsonRequest(map[string]interface{}{
+				"layout": "bogus",
+				"width":  100,
+				"height": 100,
+				"images": []string{"http://example.com/red.png"},
+			}))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring(`unknown layout "bogus"`))
+		})
+
+		It("times out slow fetches", func() {
+			config.Timeout = 10 * time.Millisecond
+			config.Fetcher = FetcherFunc(func(ctx context.Context, url string) (image.Image, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+			serve(jsonRequest(map[string]interface{}{
+				"layout": "grid",
+				"width":  100,
+				"images": []string{"http://example.com/red.png"},
+			}))
+			Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		})
+	})
+
+	It("limits the number of renders, including abandoned ones", func() {
+		config.Timeout = 50 * time.Millisecond
+		config.MaxConcurrentRenders = 1
+		fetched["http://example.com/large.png"] = uniformImage(2000, 2000, color.RGBA{0xff, 0x00, 0x00, 0xff})
+		h := NewHandler(config)
+		request := func() *http.Request {
+			return jsonRequest(map[string]interface{}{
+				"layout": "top_heavy",
+				"width":  4096,
+				"height": 4096,
+				"images": []string{"http://example.com/large.png", "http://example.com/large.png"},
+			})
+		}
+
+		h.ServeHTTP(recorder, request())
+		Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		// The first render is still running, so the second one doesn't even start
+		recorder = httptest.NewRecorder()
+		h.ServeHTTP(recorder, request())
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	Context("with a multipart body", func() {
+		It("composes the uploaded images", func() {
+			serve(multipartRequest(map[string]string{
+				"layout": "golden_spiral",
+				"width":  "200",
+				"height": "100",
+			}, fetched["http://example.com/red.png"], fetched["http://example.com/blue.png"]))
+			i := decodeResponse()
+			Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 200, 100)))
+			Expect(i.At(10, 50)).To(Equal(color.RGBA{0xff, 0x00, 0x00, 0xff}))
+			Expect(i.At(190, 50)).To(Equal(color.RGBA{0x00, 0x00, 0xff, 0xff}))
+		})
+
+		It("encodes the result as a JPEG if requested", func() {
+			serve(multipartRequest(map[string]string{
+				"layout": "grid",
+				"width":  "200",
+				"format": "jpeg",
+			}, fetched["http://example.com/red.png"]))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("image/jpeg"))
+		})
+
+		It("rejects bodies over the size limit", func() {
+			config.MaxRequestBytes = 100
+			serve(multipartRequest(map[string]string{
+				"layout": "grid",
+				"width":  "200",
+			}, fetched["http://example.com/red.png"]))
+			Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+
+		It("rejects images with too many pixels", func() {
+			config.MaxImagePixels = 40*30 - 1
+			serve(multipartRequest(map[string]string{
+				"layout": "grid",
+				"width":  "200",
+			}, fetched["http://example.com/red.png"]))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("exceeds 1199 pixels"))
+		})
+
+		It("logs errors of writing the composed image", func() {
+			var log bytes.Buffer
+			config.ErrorLog = stdlog.New(&log, "", 0)
+			NewHandler(config).ServeHTTP(failingWriter{recorder}, multipartRequest(map[string]string{
+				"layout": "grid",
+				"width":  "200",
+			}, fetched["http://example.com/red.png"]))
+			Expect(log.String()).To(ContainSubstring("writing the composed image: connection reset"))
+		})
+
+		It("rejects too many images", func() {
+			config.MaxImages = 1
+			serve(multipartRequest(map[string]string{
+				"layout": "grid",
+				"width":  "200",
+			}, fetched["http://example.com/red.png"], fetched["http://example.com/blue.png"]))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	DescribeTable("invalid parameters",
+		func(fields map[string]string) {
+			serve(multipartRequest(fields, fetched["http://example.com/red.png"]))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		},
+		Entry("unknown layout", map[string]string{"layout": "spiral", "width": "100", "height": "100"}),
+		Entry("missing width", map[string]string{"layout": "top_heavy", "height": "100"}),
+		Entry("missing height", map[string]string{"layout": "top_heavy", "width": "100"}),
+		Entry("too large width", map[string]string{"layout": "top_heavy", "width": "10000", "height": "100"}),
+		Entry("too wide border for the width", map[string]string{"layout": "top_heavy", "width": "10", "height": "100", "border_width": "5"}),
+		Entry("too wide border for the height", map[string]string{"layout": "top_heavy", "width": "100", "height": "10", "border_width": "5"}),
+		Entry("too wide border for the grid height", map[string]string{"layout": "grid", "width": "20", "border_width": "8"}),
+		Entry("non-numeric width", map[string]string{"layout": "top_heavy", "width": "wide", "height": "100"}),
+		Entry("invalid border color", map[string]string{"layout": "grid", "width": "100", "border_color": "gray"}),
+		Entry("unknown format", map[string]string{"layout": "grid", "width": "100", "format": "gif"}),
+	)
+
+	It("only accepts POST requests", func() {
+		serve(httptest.NewRequest("GET", "/", nil))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
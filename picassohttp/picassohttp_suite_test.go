@@ -0,0 +1,13 @@
+package picassohttp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPicassohttp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Picassohttp Suite")
+}
@@ -0,0 +1,149 @@
+package picasso
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"reflect"
+)
+
+// Placement describes where a single Picture ended up in a composed image.
+type Placement struct {
+	// Index is the position of the Picture among all the Pictures of the drawn tree in depth-first order. Use
+	// IndexPlacements to replace it with the position of the picture in the list of images given to a layout.
+	Index int
+	// Picture is the image of the Picture node.
+	Picture image.Image
+	// Rect is the area of the composed image that the picture occupies, borders excluded.
+	Rect image.Rectangle
+	// Crop is the part of the picture, in the picture's own coordinates, that is visible in Rect.
+	Crop image.Rectangle
+}
+
+// Placements calculates the placements of all the Pictures in the tree without drawing anything. A borderWidth of 0
+// gives the placements of Draw and any other width the placements of DrawWithBorder. Custom Node implementations are
+// opaque to this function, so no placements are returned for any pictures they might contain.
+func Placements(n Node, width, height, borderWidth int) []Placement {
+	var placements []Placement
+	for _, a := range paintedAreas(n, width, height, borderWidth) {
+		if a.background == nil {
+			placements = append(placements, a.placement)
+		}
+	}
+	return placements
+}
+
+// paintedArea is either the placement of a picture or an area that's painted with the Background of an AspectLocked
+// or a Canvas node
+type paintedArea struct {
+	placement Placement
+	// background is the color of an area that's painted with a background and nil for pictures
+	background color.Color
+	rect       image.Rectangle
+}
+
+// paintedAreas returns the pictures and the backgrounds of the tree in the order that Draw paints them, so that
+// documents which are written from the placements, such as those of WriteSVG and WritePDF, would stack them the same
+// way. The filters, captions, watermarks and the frames and rotations of canvas items are left out.
+func paintedAreas(n Node, width, height, borderWidth int) []paintedArea {
+	var areas []paintedArea
+	pictures := 0
+	Walk(n, width, height, borderWidth, func(n Node, rect image.Rectangle) bool {
+		switch n := n.(type) {
+		case Picture:
+			inBorderRect := insetRect(rect, borderWidth)
+			areas = append(areas, paintedArea{placement: Placement{
+				Index:   pictures,
+				Picture: n.Picture,
+				Rect:    inBorderRect,
+				Crop:    n.cropRect(inBorderRect.Dx(), inBorderRect.Dy()),
+			}})
+			pictures++
+		case AspectLocked:
+			if n.Background != nil {
+				areas = append(areas, paintedArea{background: n.Background, rect: n.backgroundRect(rect, borderWidth)})
+			}
+		case Canvas:
+			if n.Background != nil {
+				areas = append(areas, paintedArea{background: n.Background, rect: rect})
+			}
+		}
+		return true
+	})
+	return areas
+}
+
+// DrawWithPlacements draws the node just like Node.Draw does and also returns the placements of all of its pictures.
+func DrawWithPlacements(n Node, width, height int) (image.Image, []Placement) {
+	return n.Draw(width, height), Placements(n, width, height, 0)
+}
+
+// DrawWithBorderAndPlacements draws the node just like Node.DrawWithBorder does and also returns the placements of
+// all of its pictures.
+func DrawWithBorderAndPlacements(n Node, width, height int, borderColor color.Color, borderWidth int) (image.Image, []Placement) {
+	return n.DrawWithBorder(width, height, borderColor, borderWidth), Placements(n, width, height, borderWidth)
+}
+
+// IndexPlacements sets the Index of every placement to the position of its picture in the provided list of images,
+// which is usually the list that was given to a Layout. Placements with pictures not found in the list get an Index
+// of -1.
+func IndexPlacements(placements []Placement, images []image.Image) {
+	for i := range placements {
+		placements[i].Index = indexOfImage(images, placements[i].Picture)
+	}
+}
+
+func indexOfImage(images []image.Image, target image.Image) int {
+	// Comparing interface values with non-comparable dynamic types would panic
+	if target == nil || !reflect.TypeOf(target).Comparable() {
+		return -1
+	}
+	for i, image := range images {
+		if image != nil && reflect.TypeOf(image) == reflect.TypeOf(target) && image == target {
+			return i
+		}
+	}
+	return -1
+}
+
+type jsonRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func newJSONRect(r image.Rectangle) jsonRect {
+	return jsonRect{r.Min.X, r.Min.Y, r.Dx(), r.Dy()}
+}
+
+// MarshalJSON encodes the placement as an object with the index and the rect and crop rectangles as x, y, width and
+// height values. The picture itself is left out.
+func (p Placement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Index int      `json:"index"`
+		Rect  jsonRect `json:"rect"`
+		Crop  jsonRect `json:"crop"`
+	}{p.Index, newJSONRect(p.Rect), newJSONRect(p.Crop)})
+}
+
+// WriteHTMLMap writes an HTML <map> element with the given name and a rectangular <area> for every placement. The
+// link and the alternative text of every area is provided by the area function.
+func WriteHTMLMap(w io.Writer, name string, placements []Placement, area func(Placement) (href, alt string)) error {
+	if _, err := fmt.Fprintf(w, "<map name=\"%s\">\n", html.EscapeString(name)); err != nil {
+		return err
+	}
+	for _, p := range placements {
+		href, alt := area(p)
+		_, err := fmt.Fprintf(w, "  <area shape=\"rect\" coords=\"%d,%d,%d,%d\" href=\"%s\" alt=\"%s\">\n",
+			p.Rect.Min.X, p.Rect.Min.Y, p.Rect.Max.X, p.Rect.Max.Y, html.EscapeString(href), html.EscapeString(alt))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "</map>\n")
+	return err
+}
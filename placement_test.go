@@ -0,0 +1,132 @@
+package picasso_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Placements", func() {
+	var (
+		red    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		green  = color.RGBA{0x00, 0xff, 0x00, 0xff}
+		blue   = color.RGBA{0x00, 0x00, 0xff, 0xff}
+		yellow = color.RGBA{0xff, 0xff, 0x00, 0xff}
+		gray   = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		images []image.Image
+		node   Node
+	)
+
+	BeforeEach(func() {
+		images = []image.Image{
+			uniformImage(300, 200, red),
+			uniformImage(100, 200, green),
+			uniformImage(200, 200, blue),
+			uniformImage(200, 100, yellow),
+		}
+		node = HorizontalSplit{
+			Ratio: 2,
+			Top:   Picture{images[0]},
+			Bottom: VerticalSplit{
+				Ratio: 0.5,
+				Left:  Picture{images[1]},
+				Right: VerticalSplit{
+					Ratio: 1,
+					Left:  Picture{images[2]},
+					Right: Picture{images[3]},
+				},
+			},
+		}
+	})
+
+	It("returns the rectangles of all pictures in depth-first order", func() {
+		placements := Placements(node, 400, 600, 0)
+		Expect(placements).To(HaveLen(4))
+		for i, p := range placements {
+			Expect(p.Index).To(Equal(i))
+			Expect(p.Picture).To(Equal(images[i]))
+		}
+		Expect(placements[0].Rect).To(Equal(image.Rect(0, 0, 400, 400)))
+		Expect(placements[1].Rect).To(Equal(image.Rect(0, 400, 134, 600)))
+		Expect(placements[2].Rect).To(Equal(image.Rect(134, 400, 267, 600)))
+		Expect(placements[3].Rect).To(Equal(image.Rect(267, 400, 400, 600)))
+	})
+
+	It("returns the visible part of every picture", func() {
+		placements := Placements(node, 400, 600, 0)
+		Expect(placements[0].Crop).To(Equal(image.Rect(50, 0, 250, 200)))
+		Expect(placements[1].Crop).To(Equal(image.Rect(0, 25, 100, 174)))
+		Expect(placements[3].Crop).To(Equal(image.Rect(66, 0, 133, 100)))
+	})
+
+	It("matches what gets drawn with borders", func() {
+		i, placements := DrawWithBorderAndPlacements(node, 400, 600, gray, 3)
+		colors := []color.Color{red, green, blue, yellow}
+		for n, p := range placements {
+			Expect(i.At(p.Rect.Min.X, p.Rect.Min.Y)).To(Equal(colors[n]))
+			Expect(i.At(p.Rect.Max.X-1, p.Rect.Max.Y-1)).To(Equal(colors[n]))
+			Expect(i.At(p.Rect.Min.X-1, p.Rect.Min.Y-1)).To(Equal(gray))
+			Expect(i.At(p.Rect.Max.X, p.Rect.Max.Y)).To(Equal(gray))
+		}
+	})
+
+	It("matches what gets drawn without borders", func() {
+		i, placements := DrawWithPlacements(node, 400, 600)
+		colors := []color.Color{red, green, blue, yellow}
+		for n, p := range placements {
+			Expect(i.At(p.Rect.Min.X, p.Rect.Min.Y)).To(Equal(colors[n]))
+			Expect(i.At(p.Rect.Max.X-1, p.Rect.Max.Y-1)).To(Equal(colors[n]))
+		}
+	})
+
+	Describe("IndexPlacements", func() {
+		It("sets indexes to the positions of the images given to the layout", func() {
+			placements := Placements(GoldenSpiralLayout().Compose(images), 600, 600, 0)
+			Expect(placements[2].Index).To(Equal(2))
+			Expect(placements[2].Picture).To(Equal(images[3]))
+
+			IndexPlacements(placements, images)
+			Expect(placements[0].Index).To(Equal(0))
+			Expect(placements[1].Index).To(Equal(1))
+			Expect(placements[2].Index).To(Equal(3))
+			Expect(placements[3].Index).To(Equal(2))
+		})
+
+		It("uses -1 for unknown images", func() {
+			placements := Placements(Picture{images[0]}, 100, 100, 0)
+			IndexPlacements(placements, images[1:])
+			Expect(placements[0].Index).To(Equal(-1))
+		})
+	})
+
+	It("encodes to JSON", func() {
+		placements := Placements(Picture{images[0]}, 100, 100, 2)
+		data, err := json.Marshal(placements)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`[{
+			"index": 0,
+			"rect": {"x": 2, "y": 2, "width": 96, "height": 96},
+			"crop": {"x": 50, "y": 0, "width": 200, "height": 200}
+		}]`))
+	})
+
+	It("writes an HTML map", func() {
+		placements := Placements(node, 400, 600, 0)[:2]
+		var buf bytes.Buffer
+		err := WriteHTMLMap(&buf, "collage", placements, func(p Placement) (string, string) {
+			return "/images/" + string(rune('a'+p.Index)), `"quoted"`
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal(`<map name="collage">
+  <area shape="rect" coords="0,0,400,400" href="/images/a" alt="&#34;quoted&#34;">
+  <area shape="rect" coords="0,400,134,600" href="/images/b" alt="&#34;quoted&#34;">
+</map>
+`))
+	})
+})
@@ -0,0 +1,65 @@
+package picasso
+
+import (
+	"image"
+
+	"github.com/disintegration/gift"
+)
+
+// Quality determines how a Renderer resizes pictures. A Renderer is the only way to choose it: the Draw methods of the
+// nodes, and so those of the nodes composed by the layouts, as well as DrawGridLayout, always draw with FinalQuality.
+// To draw a layout with another quality, draw the composed node, or call DrawGridLayout, through a Renderer:
+//
+//	Renderer{Quality: DraftQuality}.Draw(TopHeavyLayout().Compose(images), width, height)
+type Quality int
+
+const (
+	// FinalQuality resizes pictures with Lanczos resampling, just like Picture.Draw does.
+	FinalQuality Quality = iota
+	// DraftQuality first shrinks large pictures by sampling the nearest pixels and then resizes them with box
+	// resampling. It's many times faster than FinalQuality, which makes it suitable for interactive previews, and it
+	// produces the exact same geometry, so a draft could be replaced with the final image without anything moving.
+	DraftQuality
+)
+
+func (q Quality) resampling() gift.Resampling {
+	if q == DraftQuality {
+		return gift.BoxResampling
+	}
+	return gift.LanczosResampling
+}
+
+func (q Quality) interpolation() gift.Interpolation {
+	if q == DraftQuality {
+		return gift.LinearInterpolation
+	}
+	return gift.CubicInterpolation
+}
+
+func (q Quality) String() string {
+	if q == DraftQuality {
+		return "draft"
+	}
+	return "final"
+}
+
+// preShrink samples the picture down by an integer factor, so that the visible part of it would still be at least
+// twice as wide as it's going to be drawn. Nearest neighbour sampling only looks at a single source pixel for every
+// destination pixel, which makes it very fast for large sources, and the box resampling that follows smooths out the
+// worst of its artifacts.
+func preShrink(picture image.Image, cropWidth, width int) image.Image {
+	factor := 0
+	if width > 0 {
+		factor = cropWidth / (2 * width)
+	}
+	if factor < 2 {
+		return picture
+	}
+	bounds := picture.Bounds()
+	g := gift.New(
+		gift.Resize(bounds.Dx()/factor, bounds.Dy()/factor, gift.NearestNeighborResampling),
+	)
+	dst := image.NewRGBA(g.Bounds(bounds))
+	g.Draw(dst, picture)
+	return dst
+}
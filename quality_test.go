@@ -0,0 +1,84 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quality", func() {
+	var (
+		red    = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		images []image.Image
+		draft  = Renderer{Quality: DraftQuality}
+		final  = Renderer{Quality: FinalQuality}
+	)
+
+	BeforeEach(func() {
+		images = []image.Image{
+			GirlBeforeAMirror.read(),
+			OldGuitarist.read(),
+			WomenOfAlgiers.read(),
+			Bullfight.read(),
+		}
+	})
+
+	// borderMask marks the pixels that have the border color, which outlines the cell rectangles
+	borderMask := func(i image.Image) []bool {
+		var mask []bool
+		b := i.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				mask = append(mask, i.At(x, y) == red)
+			}
+		}
+		return mask
+	}
+
+	It("draws final images exactly like the nodes do", func() {
+		node := TopHeavyLayout().Compose(images)
+		Expect(final.Draw(node, 200, 300)).To(Equal(node.Draw(200, 300)))
+	})
+
+	It("draws drafts that differ from final images", func() {
+		node := TopHeavyLayout().Compose(images)
+		Expect(draft.Draw(node, 200, 300)).NotTo(Equal(final.Draw(node, 200, 300)))
+	})
+
+	for _, layout := range []struct {
+		name   string
+		layout Layout
+	}{
+		{"TopHeavyLayout", TopHeavyLayout()},
+		{"GoldenSpiralLayout", GoldenSpiralLayout()},
+	} {
+		layout := layout
+		It("draws drafts with identical cells for the "+layout.name, func() {
+			node := layout.layout.Compose(images)
+			draftImage := draft.DrawWithBorder(node, 300, 200, red, 2)
+			finalImage := final.DrawWithBorder(node, 300, 200, red, 2)
+			Expect(draftImage.Bounds()).To(Equal(finalImage.Bounds()))
+			Expect(borderMask(draftImage)).To(Equal(borderMask(finalImage)))
+		})
+	}
+
+	It("draws drafts with identical cells for the grid layout", func() {
+		draftImage := draft.DrawGridLayoutWithBorder(images, 300, red, 2)
+		finalImage := final.DrawGridLayoutWithBorder(images, 300, red, 2)
+		Expect(draftImage.Bounds()).To(Equal(finalImage.Bounds()))
+		Expect(borderMask(draftImage)).To(Equal(borderMask(finalImage)))
+		Expect(final.DrawGridLayout(images, 300)).To(Equal(DrawGridLayout(images, 300)))
+	})
+
+	It("draws uniform pictures identically", func() {
+		node := GoldenSpiralLayout().Compose([]image.Image{
+			uniformImage(3000, 2000, color.RGBA{0x00, 0xff, 0x00, 0xff}),
+			uniformImage(200, 300, color.RGBA{0x00, 0x00, 0xff, 0xff}),
+		})
+		Expect(draft.DrawWithBorder(node, 300, 200, red, 2)).To(Equal(final.DrawWithBorder(node, 300, 200, red, 2)))
+	})
+})
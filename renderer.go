@@ -0,0 +1,149 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+
+	"github.com/disintegration/gift"
+)
+
+// Renderer draws nodes with settings that the Draw and DrawWithBorder methods of the nodes themselves don't provide.
+// The zero value draws exactly like the nodes do. Custom Node implementations are drawn with their own methods, so
+// the settings of the renderer don't apply to any pictures within them.
+type Renderer struct {
+	// Cache, if set, is used to reuse pictures that have already been resized to the same size before. Only
+	// pictures with comparable images, such as pointers to decoded images or Sources, are cached.
+	Cache ResizeCache
+	// Quality determines how the pictures are resized. The geometry of the composed image doesn't depend on it.
+	Quality Quality
+	// OnDecodeError, if set, is called with the picture and the error whenever the pixels of a Source can't be
+	// decoded. The picture is left transparent either way, so that a single broken image wouldn't spoil the whole
+	// collage.
+	OnDecodeError func(picture image.Image, err error)
+
+	// downscales maps pictures to smaller versions of themselves that they can be resized from, see DrawWidths
+	downscales map[image.Image]intermediateImage
+	// filters are the filters of all the Filtered nodes that the node being painted is nested in, innermost first
+	filters []gift.Filter
+	// border is the image that the borders are painted with, see BorderFill
+	border image.Image
+}
+
+// Draw draws the node just like Node.Draw does, but with the settings of the renderer.
+func (r Renderer) Draw(n Node, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	r.paint(dst, n, dst.Bounds(), nil, 0)
+	return dst
+}
+
+// DrawWithBorder draws the node just like Node.DrawWithBorder does, but with the settings of the renderer.
+func (r Renderer) DrawWithBorder(n Node, width, height int, borderColor color.Color, borderWidth int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	borderColor = resolveBorderColor(n, borderColor)
+	r.border = borderImage(borderColor, width, height)
+	r.paint(dst, n, dst.Bounds(), borderColor, borderWidth)
+	return dst
+}
+
+// paint draws the node into the given area of dst. Unlike the nested Draw calls of the nodes, which compose images
+// of their children, all nodes are painted directly onto the same destination image. The border of the renderer has
+// to be set if borderWidth is non-zero.
+func (r Renderer) paint(dst *image.RGBA, n Node, rect image.Rectangle, borderColor color.Color, borderWidth int) {
+	switch n := n.(type) {
+	case Picture:
+		inBorderRect := rect
+		if borderWidth > 0 {
+			// The border is painted in the coordinates of the whole canvas, so that fills would be continuous
+			draw.Draw(dst, rect, r.border, rect.Min, draw.Over)
+			inBorderRect = insetRect(rect, borderWidth)
+		}
+		picture := applyFilters(r.drawPicture(n, inBorderRect.Dx(), inBorderRect.Dy()), r.filters)
+		draw.Draw(dst, inBorderRect, picture, image.ZP, draw.Over)
+	case VerticalSplit:
+		leftRect, rightRect := n.childRects(rect.Dx(), rect.Dy(), borderWidth)
+		r.paint(dst, n.Left, leftRect.Add(rect.Min), borderColor, borderWidth)
+		r.paint(dst, n.Right, rightRect.Add(rect.Min), borderColor, borderWidth)
+	case HorizontalSplit:
+		topRect, bottomRect := n.childRects(rect.Dx(), rect.Dy(), borderWidth)
+		r.paint(dst, n.Top, topRect.Add(rect.Min), borderColor, borderWidth)
+		r.paint(dst, n.Bottom, bottomRect.Add(rect.Min), borderColor, borderWidth)
+	case Captioned:
+		nodeRect, band := n.rects(rect.Dx(), rect.Dy(), borderWidth)
+		r.paint(dst, n.Node, nodeRect.Add(rect.Min), borderColor, borderWidth)
+		if borderWidth > 0 {
+			n.paintBandBorder(dst, rect, band.Add(rect.Min), r.border)
+		}
+		n.paintCaption(dst, band.Add(rect.Min))
+	case Filtered:
+		inner := r
+		// A full slice expression makes sure that appending doesn't modify the filters of the node
+		inner.filters = append(n.Filters[:len(n.Filters):len(n.Filters)], r.filters...)
+		inner.paint(dst, n.Node, rect, borderColor, borderWidth)
+	case Grid:
+		n.paintGaps(dst, rect, r.border, borderWidth)
+		for i, cellRect := range n.cellRects(rect.Dx(), rect.Dy(), borderWidth) {
+			if !cellRect.Empty() {
+				r.paint(dst, n.Cells[i].Node, cellRect.Add(rect.Min), borderColor, borderWidth)
+			}
+		}
+	case Canvas:
+		n.paintBackground(dst, rect, r.border, borderWidth)
+		for _, i := range n.drawOrder() {
+			r.paintCanvasItem(dst, n.Items[i], rect, borderColor, borderWidth)
+		}
+	case AspectLocked:
+		n.paintPadding(dst, rect, r.border, borderWidth)
+		r.paint(dst, n.Node, n.nodeRect(rect.Dx(), rect.Dy(), borderWidth).Add(rect.Min), borderColor, borderWidth)
+	case Watermark:
+		r.paint(dst, n.Node, rect, borderColor, borderWidth)
+		n.paintWatermark(dst, insetRect(rect, borderWidth), r.Quality)
+	default:
+		var m image.Image
+		if borderWidth > 0 {
+			m = n.DrawWithBorder(rect.Dx(), rect.Dy(), borderColor, borderWidth)
+		} else {
+			m = n.Draw(rect.Dx(), rect.Dy())
+		}
+		draw.Draw(dst, rect, m, m.Bounds().Min, draw.Over)
+	}
+}
+
+// drawPicture resizes the picture to fill the given size, going through the cache if there is one
+func (r Renderer) drawPicture(p Picture, width, height int) image.Image {
+	cached := r.Cache != nil && reflect.TypeOf(p.Picture).Comparable()
+	key := ResizeKey{
+		Source: p.Picture,
+		Crop:   p.cropRect(width, height),
+		Width:  width,
+		Height: height,
+		Filter: r.Quality.String(),
+	}
+	if cached {
+		if m, ok := r.Cache.Get(key); ok {
+			return m
+		}
+	}
+	intermediate, downscaled := r.downscaled(p, width, height)
+	var m image.Image
+	var err error
+	if downscaled {
+		m, err = Picture{intermediate}.draw(width, height, r.Quality)
+	} else {
+		m, err = p.draw(width, height, r.Quality)
+	}
+	if err != nil {
+		// The empty image isn't cached, so that the picture would be decoded again if the error was temporary
+		if r.OnDecodeError != nil {
+			r.OnDecodeError(p.Picture, err)
+		}
+		return m
+	}
+	// Pictures resized from an intermediate image have been resampled twice, so they can't stand in for pictures
+	// resized straight from the source under the same key
+	if cached && !downscaled {
+		r.Cache.Put(key, m)
+	}
+	return m
+}
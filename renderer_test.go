@@ -0,0 +1,58 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// customNode is a Node implementation that the package knows nothing about
+type customNode struct {
+	color color.Color
+}
+
+func (n customNode) Draw(width, height int) image.Image {
+	return uniformImage(width, height, n.color)
+}
+
+func (n customNode) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Picture{uniformImage(width, height, n.color)}.DrawWithBorder(width, height, borderColor, borderWidth)
+}
+
+var _ = Describe("Renderer", func() {
+	var node Node
+
+	BeforeEach(func() {
+		node = HorizontalSplit{
+			Ratio: 2,
+			Top:   Picture{Bullfight.read()},
+			Bottom: VerticalSplit{
+				Ratio: 0.5,
+				Left:  Picture{GirlBeforeAMirror.read()},
+				Right: VerticalSplit{
+					Ratio: 1,
+					Left:  Picture{uniformImage(10, 10, color.RGBA{0x00, 0x00, 0xff, 0x80})},
+					Right: customNode{color.RGBA{0x00, 0xff, 0x00, 0xff}},
+				},
+			},
+		}
+	})
+
+	It("draws exactly like the nodes do", func() {
+		Expect(Renderer{}.Draw(node, 200, 300)).To(Equal(node.Draw(200, 300)))
+	})
+
+	It("draws borders exactly like the nodes do", func() {
+		gray := color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		Expect(Renderer{}.DrawWithBorder(node, 200, 300, gray, 3)).To(Equal(node.DrawWithBorder(200, 300, gray, 3)))
+	})
+
+	It("draws translucent borders exactly like the nodes do", func() {
+		translucent := color.RGBA{0x40, 0x00, 0x00, 0x40}
+		Expect(Renderer{}.DrawWithBorder(node, 200, 300, translucent, 2)).To(Equal(node.DrawWithBorder(200, 300, translucent, 2)))
+	})
+})
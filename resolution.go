@@ -0,0 +1,144 @@
+package picasso
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// PictureResolution describes how much a single picture is scaled when a tree is drawn at a given size.
+type PictureResolution struct {
+	Placement Placement
+	// Scale is the factor that the visible part of the picture is resized by. Values over 1 mean that the picture is
+	// enlarged.
+	Scale float64
+}
+
+// Upscaled reports whether the picture is enlarged.
+func (r PictureResolution) Upscaled() bool {
+	return r.Scale > 1
+}
+
+// AnalyzeResolution calculates the scale factor of every picture in the tree when it's drawn at the given size. A
+// borderWidth of 0 analyzes Draw and any other width DrawWithBorder.
+func AnalyzeResolution(n Node, width, height, borderWidth int) []PictureResolution {
+	placements := Placements(n, width, height, borderWidth)
+	resolutions := make([]PictureResolution, len(placements))
+	for i, p := range placements {
+		resolutions[i] = PictureResolution{p, placementScale(p)}
+	}
+	return resolutions
+}
+
+// placementScale returns the factor that the visible part of the picture is enlarged by when it's drawn into its cell.
+// The crop has the same aspect ratio as the cell, so looking at the widths is enough.
+func placementScale(p Placement) float64 {
+	if p.Crop.Dx() == 0 {
+		return 0
+	}
+	return float64(p.Rect.Dx()) / float64(p.Crop.Dx())
+}
+
+// UpscalePolicy determines what ResolutionGuard does with pictures that are enlarged too much.
+type UpscalePolicy int
+
+const (
+	// WarnOnUpscaling leaves the tree as it is and only reports the pictures.
+	WarnOnUpscaling UpscalePolicy = iota
+	// RejectUpscaling returns an UpscalingError.
+	RejectUpscaling
+	// RearrangeUpscaled moves the pictures around so that the ones with the lowest resolution would end up in the
+	// smallest cells. Pictures are only swapped with others of the same orientation, so that the layouts, which
+	// often rely on orientations, wouldn't crop them too much. Any pictures still enlarged too much are reported.
+	RearrangeUpscaled
+)
+
+// ResolutionGuard checks trees for pictures that would be enlarged more than MaxScale times.
+type ResolutionGuard struct {
+	Policy UpscalePolicy
+	// MaxScale is the largest factor that a picture can be enlarged by. Values below 1 are treated as 1.
+	MaxScale float64
+}
+
+// UpscalingError lists the pictures that would be enlarged too much.
+type UpscalingError struct {
+	Resolutions []PictureResolution
+}
+
+func (e UpscalingError) Error() string {
+	return fmt.Sprintf("%d picture(s) would be enlarged too much, the first one %.2f times", len(e.Resolutions),
+		e.Resolutions[0].Scale)
+}
+
+// Check analyzes the tree at the given size and applies the policy of the guard. It returns the tree to be drawn,
+// which only differs from the given one for RearrangeUpscaled, and the pictures that would be enlarged too much in
+// that tree. An UpscalingError is only returned for RejectUpscaling.
+func (g ResolutionGuard) Check(n Node, width, height, borderWidth int) (Node, []PictureResolution, error) {
+	if g.Policy == RearrangeUpscaled {
+		n = rearrangeByResolution(n, width, height, borderWidth)
+	}
+
+	maxScale := g.MaxScale
+	if maxScale < 1 {
+		maxScale = 1
+	}
+	var upscaled []PictureResolution
+	for _, r := range AnalyzeResolution(n, width, height, borderWidth) {
+		if r.Scale > maxScale {
+			upscaled = append(upscaled, r)
+		}
+	}
+
+	if g.Policy == RejectUpscaling && len(upscaled) > 0 {
+		return nil, upscaled, UpscalingError{upscaled}
+	}
+	return n, upscaled, nil
+}
+
+// rearrangeByResolution assigns the pictures with the most pixels to the largest cells, separately for both
+// orientations.
+func rearrangeByResolution(n Node, width, height, borderWidth int) Node {
+	placements := Placements(n, width, height, borderWidth)
+	assigned := make([]image.Image, len(placements))
+	for _, o := range []orientation{horizontal, vertical} {
+		var cells []int
+		var pictures []image.Image
+		for i, p := range placements {
+			if getImageOrientation(p.Picture) == o {
+				cells = append(cells, i)
+				pictures = append(pictures, p.Picture)
+			}
+		}
+		sort.SliceStable(cells, func(a, b int) bool {
+			return area(placements[cells[a]].Rect) > area(placements[cells[b]].Rect)
+		})
+		sort.SliceStable(pictures, func(a, b int) bool {
+			return area(pictures[a].Bounds()) > area(pictures[b].Bounds())
+		})
+		for i, cell := range cells {
+			assigned[cell] = pictures[i]
+		}
+	}
+
+	next := 0
+	return replacePictures(n, func(p Picture) Node {
+		p.Picture = assigned[next]
+		next++
+		return p
+	})
+}
+
+func area(r image.Rectangle) int {
+	return r.Dx() * r.Dy()
+}
+
+// replacePictures rebuilds the tree with every Picture replaced by the result of the replace function, which is
+// called in the same depth-first order that Placements uses.
+func replacePictures(n Node, replace func(Picture) Node) Node {
+	return Map(n, func(n Node) Node {
+		if p, ok := n.(Picture); ok {
+			return replace(p)
+		}
+		return n
+	})
+}
@@ -0,0 +1,88 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolution", func() {
+	var (
+		small, large, portrait image.Image
+		node                   Node
+	)
+
+	BeforeEach(func() {
+		small = uniformImage(50, 50, color.RGBA{0xff, 0x00, 0x00, 0xff})
+		large = uniformImage(400, 400, color.RGBA{0x00, 0xff, 0x00, 0xff})
+		portrait = uniformImage(10, 20, color.RGBA{0x00, 0x00, 0xff, 0xff})
+		node = VerticalSplit{
+			Ratio: 2,
+			Left:  Picture{small},
+			Right: Picture{large},
+		}
+	})
+
+	Describe("AnalyzeResolution", func() {
+		It("reports the scale of every picture", func() {
+			resolutions := AnalyzeResolution(node, 300, 100, 0)
+			Expect(resolutions).To(HaveLen(2))
+			Expect(resolutions[0].Placement.Picture).To(Equal(small))
+			Expect(resolutions[0].Scale).To(BeNumerically("~", 4))
+			Expect(resolutions[0].Upscaled()).To(BeTrue())
+			Expect(resolutions[1].Scale).To(BeNumerically("~", 0.25))
+			Expect(resolutions[1].Upscaled()).To(BeFalse())
+		})
+	})
+
+	Describe("ResolutionGuard", func() {
+		It("warns about enlarged pictures", func() {
+			checked, upscaled, err := ResolutionGuard{Policy: WarnOnUpscaling, MaxScale: 2}.Check(node, 300, 100, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checked).To(Equal(node))
+			Expect(upscaled).To(HaveLen(1))
+			Expect(upscaled[0].Placement.Picture).To(Equal(small))
+		})
+
+		It("rejects enlarged pictures", func() {
+			checked, upscaled, err := ResolutionGuard{Policy: RejectUpscaling, MaxScale: 2}.Check(node, 300, 100, 0)
+			Expect(err).To(MatchError("1 picture(s) would be enlarged too much, the first one 4.00 times"))
+			Expect(checked).To(BeNil())
+			Expect(upscaled).To(HaveLen(1))
+		})
+
+		It("accepts trees without enlarged pictures", func() {
+			_, upscaled, err := ResolutionGuard{Policy: RejectUpscaling, MaxScale: 4}.Check(node, 300, 100, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upscaled).To(BeEmpty())
+		})
+
+		It("moves low resolution pictures into smaller cells", func() {
+			checked, upscaled, err := ResolutionGuard{Policy: RearrangeUpscaled, MaxScale: 2}.Check(node, 300, 100, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upscaled).To(BeEmpty())
+			Expect(checked).To(Equal(VerticalSplit{
+				Ratio: 2,
+				Left:  Picture{large},
+				Right: Picture{small},
+			}))
+		})
+
+		It("only swaps pictures with the same orientation", func() {
+			node = HorizontalSplit{
+				Ratio:  3,
+				Top:    Picture{portrait},
+				Bottom: node,
+			}
+			checked, upscaled, err := ResolutionGuard{Policy: RearrangeUpscaled, MaxScale: 2}.Check(node, 300, 400, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upscaled).To(HaveLen(1))
+			Expect(upscaled[0].Placement.Picture).To(Equal(portrait))
+			Expect(checked.(HorizontalSplit).Top).To(Equal(Picture{portrait}))
+		})
+	})
+})
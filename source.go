@@ -0,0 +1,125 @@
+package picasso
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"sync"
+)
+
+// Source is an image whose dimensions are known up front, but whose pixels are only decoded on demand. Sources
+// implement image.Image, so they can be used anywhere an image is expected, including Picture and all the layouts.
+// Picture decodes a source only for the duration of resizing it, so that drawing a tree of sources only needs enough
+// memory for the largest source and the composed image, instead of all the sources at once.
+//
+// Since the pixels are only decoded while drawing, a source that can be opened but not decoded, e.g. a truncated
+// file, is drawn as a transparent area. Use Renderer.OnDecodeError to find out about such sources.
+type Source interface {
+	image.Image
+	// Decode decodes the pixels of the image. The source doesn't keep a reference to the result.
+	Decode() (image.Image, error)
+}
+
+// NewSource creates a Source that decodes the image from the readers returned by open. The open function is called
+// once right away to read the dimensions and the EXIF orientation of the image from its header and then again every
+// time the pixels are needed. The orientation is applied to both the dimensions and the pixels.
+func NewSource(open func() (io.ReadCloser, error)) (Source, error) {
+	r, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Keep a copy of everything read while looking for the orientation, so that the config could be decoded from
+	// the beginning of the image without opening it again
+	var header bytes.Buffer
+	orientation, err := ReadExifOrientation(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := config.Width, config.Height
+	if orientation.swapsDimensions() {
+		width, height = height, width
+	}
+	return &lazySource{
+		open:        open,
+		bounds:      image.Rect(0, 0, width, height),
+		colorModel:  config.ColorModel,
+		orientation: orientation,
+	}, nil
+}
+
+// FileSource creates a Source that decodes the image from the file at the given path, see NewSource.
+func FileSource(path string) (Source, error) {
+	return NewSource(func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+}
+
+type lazySource struct {
+	open        func() (io.ReadCloser, error)
+	bounds      image.Rectangle
+	colorModel  color.Model
+	orientation ExifOrientation
+
+	// decoded and decodeErr are only used by At, see below
+	mutex     sync.Mutex
+	decoded   image.Image
+	decodeErr error
+}
+
+func (s *lazySource) Bounds() image.Rectangle {
+	return s.bounds
+}
+
+func (s *lazySource) ColorModel() color.Model {
+	if s.orientation != OrientationNormal {
+		// The orientation is applied by drawing into an RGBA image
+		return color.RGBAModel
+	}
+	return s.colorModel
+}
+
+// At is only there to satisfy the image.Image interface. It decodes the image on the first call and keeps it in
+// memory, which defeats the purpose of the source, so it should be avoided in favor of Decode. Pixels of images that
+// can't be decoded are transparent. The error is kept as well, so that reading the pixels of such an image wouldn't
+// decode it over and over again.
+func (s *lazySource) At(x, y int) color.Color {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.decoded == nil && s.decodeErr == nil {
+		s.decoded, s.decodeErr = s.Decode()
+	}
+	if s.decodeErr != nil {
+		return color.Transparent
+	}
+	return s.decoded.At(x, y)
+}
+
+func (s *lazySource) Decode() (image.Image, error) {
+	r, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	m, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.orientation.Apply(m), nil
+}
+
+// decodePixels returns the image itself for ordinary images and the decoded pixels for Sources.
+func decodePixels(m image.Image) (image.Image, error) {
+	if s, ok := m.(Source); ok {
+		return s.Decode()
+	}
+	return m, nil
+}
@@ -0,0 +1,127 @@
+package picasso_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source", func() {
+	var (
+		encoded []byte
+		opened  int
+		open    func() (io.ReadCloser, error)
+	)
+
+	BeforeEach(func() {
+		var buf bytes.Buffer
+		Expect(png.Encode(&buf, uniformImage(40, 20, color.RGBA{0xff, 0x00, 0x00, 0xff}))).To(Succeed())
+		encoded = buf.Bytes()
+		opened = 0
+		open = func() (io.ReadCloser, error) {
+			opened++
+			return ioutil.NopCloser(bytes.NewReader(encoded)), nil
+		}
+	})
+
+	It("knows its dimensions without decoding the pixels", func() {
+		s, err := NewSource(open)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Bounds()).To(Equal(image.Rect(0, 0, 40, 20)))
+		Expect(opened).To(Equal(1))
+	})
+
+	It("decodes the pixels on demand", func() {
+		s, err := NewSource(open)
+		Expect(err).NotTo(HaveOccurred())
+		decoded, err := s.Decode()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded.At(10, 10)).To(Equal(color.RGBA{0xff, 0x00, 0x00, 0xff}))
+		Expect(opened).To(Equal(2))
+	})
+
+	It("reports invalid images right away", func() {
+		encoded = []byte("not an image")
+		_, err := NewSource(open)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reports errors from open", func() {
+		_, err := NewSource(func() (io.ReadCloser, error) {
+			return nil, errors.New("missing")
+		})
+		Expect(err).To(MatchError("missing"))
+	})
+
+	It("applies the EXIF orientation to the dimensions", func() {
+		s, err := FileSource(string(exifOrientationFixture(OrientationRotate90Clockwise)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Bounds()).To(Equal(image.Rect(0, 0, 64, 32)))
+
+		decoded, err := s.Decode()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded.Bounds()).To(Equal(image.Rect(0, 0, 64, 32)))
+		expectColorClose(decoded.At(8, 8), color.RGBA{0xff, 0x00, 0x00, 0xff})
+	})
+
+	It("can be drawn by layouts", func() {
+		var images []image.Image
+		for _, o := range []ExifOrientation{OrientationNormal, OrientationRotate90Clockwise, OrientationTransverse} {
+			s, err := FileSource(string(exifOrientationFixture(o)))
+			Expect(err).NotTo(HaveOccurred())
+			images = append(images, s)
+		}
+		i := GoldenSpiralLayout().Compose(images).Draw(64, 32)
+		expected := GoldenSpiralLayout().Compose([]image.Image{
+			exifOrientationFixture(OrientationNormal).decodeOriented(),
+			exifOrientationFixture(OrientationRotate90Clockwise).decodeOriented(),
+			exifOrientationFixture(OrientationTransverse).decodeOriented(),
+		}).Draw(64, 32)
+		Expect(i).To(Equal(expected))
+	})
+
+	It("is decoded every time a picture is drawn", func() {
+		s, err := NewSource(open)
+		Expect(err).NotTo(HaveOccurred())
+		Picture{s}.Draw(20, 20)
+		Picture{s}.Draw(10, 10)
+		Expect(opened).To(Equal(3))
+	})
+
+	It("reports pixels that can't be decoded to the renderer", func() {
+		s, err := NewSource(open)
+		Expect(err).NotTo(HaveOccurred())
+		encoded = encoded[:len(encoded)/2]
+
+		Expect(Picture{s}.Draw(20, 20).At(10, 10)).To(Equal(color.RGBA{}))
+		var failed []image.Image
+		r := Renderer{Cache: NewLRUCache(1 << 20), OnDecodeError: func(picture image.Image, err error) {
+			Expect(err).To(HaveOccurred())
+			failed = append(failed, picture)
+		}}
+		Expect(r.Draw(Picture{s}, 20, 20).At(10, 10)).To(Equal(color.RGBA{}))
+		Expect(failed).To(Equal([]image.Image{s}))
+
+		By("not caching the failures")
+		r.Draw(Picture{s}, 20, 20)
+		Expect(failed).To(HaveLen(2))
+	})
+
+	It("decodes images that can't be decoded only once when reading their pixels", func() {
+		s, err := NewSource(open)
+		Expect(err).NotTo(HaveOccurred())
+		encoded = encoded[:len(encoded)/2]
+		Expect(s.At(0, 0)).To(Equal(color.Transparent))
+		Expect(s.At(1, 0)).To(Equal(color.Transparent))
+		Expect(opened).To(Equal(2))
+	})
+})
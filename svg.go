@@ -0,0 +1,123 @@
+package picasso
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// SVGOptions configures WriteSVG.
+type SVGOptions struct {
+	// BorderColor and BorderWidth have the same meaning as the arguments of DrawWithBorder. Borders are only drawn if
+	// BorderWidth is positive.
+	BorderColor color.Color
+	BorderWidth int
+	// Href returns the link that an <image> element should use for the given picture. If Href is nil, the pictures
+	// are embedded into the document as base64 encoded data URIs instead.
+	Href func(image.Image) string
+}
+
+// WriteSVG writes the node as an SVG document that uses the exact same geometry as Draw (or DrawWithBorder if a
+// border width is provided). Every picture is an <image> element clipped to its cell and cropped to fill it the same
+// way Draw does, and the backgrounds of AspectLocked and Canvas nodes are <rect> elements stacked with the pictures in
+// the order that Draw paints them. The document leaves out the filters of Filtered nodes, the captions of Captioned
+// nodes, the overlays of Watermark nodes and the frames of canvas items, and it places the pictures of rotated canvas
+// items as if they weren't rotated. Custom Node implementations are not included in the document, see Placements.
+func WriteSVG(w io.Writer, n Node, width, height int, o SVGOptions) error {
+	borderWidth := 0
+	if o.BorderWidth > 0 {
+		borderWidth = o.BorderWidth
+	}
+	areas := paintedAreas(n, width, height, borderWidth)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`version="1.1" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+
+	fmt.Fprintf(bw, "  <defs>\n")
+	for _, a := range areas {
+		if a.background == nil {
+			p := a.placement
+			fmt.Fprintf(bw, `    <clipPath id="picasso-cell-%d">%s</clipPath>`+"\n", p.Index, svgRect(p.Rect, ""))
+		}
+	}
+	fmt.Fprintf(bw, "  </defs>\n")
+
+	if borderWidth > 0 && o.BorderColor != nil {
+		fmt.Fprintf(bw, "  %s\n", svgRect(image.Rect(0, 0, width, height), svgFill(resolveBorderColor(n, o.BorderColor))))
+	}
+
+	for _, a := range areas {
+		if a.background != nil {
+			fmt.Fprintf(bw, "  %s\n", svgRect(a.rect, svgFill(a.background)))
+			continue
+		}
+		p := a.placement
+		href, err := svgHref(p.Picture, o.Href)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, `  <image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid slice" `+
+			`clip-path="url(#picasso-cell-%d)" xlink:href="%s"/>`+"\n",
+			p.Rect.Min.X, p.Rect.Min.Y, p.Rect.Dx(), p.Rect.Dy(), p.Index, html.EscapeString(href))
+	}
+
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+func svgRect(r image.Rectangle, attributes string) string {
+	return fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d"%s/>`, r.Min.X, r.Min.Y, r.Dx(), r.Dy(), attributes)
+}
+
+func svgFill(c color.Color) string {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, nrgba.R, nrgba.G, nrgba.B)
+	if nrgba.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(nrgba.A)/0xff)
+	}
+	return fill
+}
+
+func svgHref(picture image.Image, href func(image.Image) string) (string, error) {
+	if href != nil {
+		return href(picture), nil
+	}
+	return dataURI(picture)
+}
+
+// dataURI encodes opaque pictures, which are most likely photos, as JPEGs to keep the document size sane. Pictures
+// that could have transparent parts are encoded as PNGs.
+func dataURI(picture image.Image) (string, error) {
+	picture, err := decodePixels(picture)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	opaque, ok := picture.(interface {
+		Opaque() bool
+	})
+	if ok && opaque.Opaque() {
+		buf.WriteString("data:image/jpeg;base64,")
+		enc := base64.NewEncoder(base64.StdEncoding, &buf)
+		if err := jpeg.Encode(enc, picture, &jpeg.Options{Quality: 90}); err != nil {
+			return "", err
+		}
+		enc.Close()
+	} else {
+		buf.WriteString("data:image/png;base64,")
+		enc := base64.NewEncoder(base64.StdEncoding, &buf)
+		if err := png.Encode(enc, picture); err != nil {
+			return "", err
+		}
+		enc.Close()
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,145 @@
+package picasso_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type svgDocument struct {
+	Width     int       `xml:"width,attr"`
+	Height    int       `xml:"height,attr"`
+	ClipPaths []svgClip `xml:"defs>clipPath"`
+	Rects     []svgRect `xml:"rect"`
+	Images    []struct {
+		svgRect
+		PreserveAspectRatio string `xml:"preserveAspectRatio,attr"`
+		ClipPath            string `xml:"clip-path,attr"`
+		Href                string `xml:"http://www.w3.org/1999/xlink href,attr"`
+	} `xml:"image"`
+}
+
+type svgClip struct {
+	ID   string  `xml:"id,attr"`
+	Rect svgRect `xml:"rect"`
+}
+
+type svgRect struct {
+	X      int    `xml:"x,attr"`
+	Y      int    `xml:"y,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Fill   string `xml:"fill,attr"`
+}
+
+func (r svgRect) rect() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+var _ = Describe("WriteSVG", func() {
+	var (
+		images []image.Image
+		node   Node
+	)
+
+	BeforeEach(func() {
+		images = []image.Image{
+			uniformImage(300, 200, color.RGBA{0xff, 0x00, 0x00, 0xff}),
+			uniformImage(100, 200, color.RGBA{0x00, 0xff, 0x00, 0xff}),
+			uniformImage(200, 200, color.RGBA{0x00, 0x00, 0xff, 0x80}),
+		}
+		node = GoldenSpiralLayout().Compose(images)
+	})
+
+	writeSVG := func(o SVGOptions) svgDocument {
+		var buf bytes.Buffer
+		Expect(WriteSVG(&buf, node, 600, 400, o)).To(Succeed())
+		var doc svgDocument
+		Expect(xml.Unmarshal(buf.Bytes(), &doc)).To(Succeed())
+		return doc
+	}
+
+	It("places the pictures like Draw does", func() {
+		doc := writeSVG(SVGOptions{})
+		placements := Placements(node, 600, 400, 0)
+		Expect(doc.Width).To(Equal(600))
+		Expect(doc.Height).To(Equal(400))
+		Expect(doc.Rects).To(BeEmpty())
+		Expect(doc.Images).To(HaveLen(len(placements)))
+		Expect(doc.ClipPaths).To(HaveLen(len(placements)))
+		for i, p := range placements {
+			Expect(doc.Images[i].rect()).To(Equal(p.Rect))
+			Expect(doc.Images[i].PreserveAspectRatio).To(Equal("xMidYMid slice"))
+			Expect(doc.Images[i].ClipPath).To(Equal(fmt.Sprintf("url(#%s)", doc.ClipPaths[i].ID)))
+			Expect(doc.ClipPaths[i].Rect.rect()).To(Equal(p.Rect))
+		}
+	})
+
+	It("embeds the pictures", func() {
+		doc := writeSVG(SVGOptions{})
+		Expect(doc.Images[0].Href).To(HavePrefix("data:image/jpeg;base64,"))
+		Expect(doc.Images[2].Href).To(HavePrefix("data:image/png;base64,"))
+	})
+
+	It("links to the pictures", func() {
+		doc := writeSVG(SVGOptions{
+			Href: func(i image.Image) string {
+				for n, image := range images {
+					if image == i {
+						return fmt.Sprintf("/images/%d.jpg?size=%d&x", n, i.Bounds().Dx())
+					}
+				}
+				return ""
+			},
+		})
+		Expect(doc.Images[0].Href).To(Equal("/images/0.jpg?size=300&x"))
+		Expect(doc.Images[1].Href).To(Equal("/images/1.jpg?size=100&x"))
+	})
+
+	It("draws the borders", func() {
+		doc := writeSVG(SVGOptions{
+			BorderColor: color.RGBA{0xaf, 0xaf, 0xaf, 0xff},
+			BorderWidth: 2,
+		})
+		Expect(doc.Rects).To(HaveLen(1))
+		Expect(doc.Rects[0].rect()).To(Equal(image.Rect(0, 0, 600, 400)))
+		Expect(doc.Rects[0].Fill).To(Equal("#afafaf"))
+		for i, p := range Placements(node, 600, 400, 2) {
+			Expect(doc.Images[i].rect()).To(Equal(p.Rect))
+		}
+	})
+
+	It("draws the backgrounds of the nodes under the pictures within them", func() {
+		node = AspectLocked{
+			AspectRatio: 1,
+			Background:  color.RGBA{0x00, 0x00, 0xff, 0xff},
+			Node: Canvas{Background: color.RGBA{0xff, 0xff, 0x00, 0xff}, Items: []CanvasItem{
+				{Node: Picture{images[0]}, X: 0.5, Y: 0.5, Width: 0.5, Height: 0.5},
+			}},
+		}
+		doc := writeSVG(SVGOptions{Href: func(image.Image) string { return "" }})
+		Expect(doc.Rects).To(Equal([]svgRect{
+			{X: 0, Y: 0, Width: 600, Height: 400, Fill: "#0000ff"},
+			{X: 100, Y: 0, Width: 400, Height: 400, Fill: "#ffff00"},
+		}))
+		Expect(doc.Images).To(HaveLen(1))
+		Expect(doc.Images[0].rect()).To(Equal(image.Rect(200, 100, 400, 300)))
+	})
+
+	It("escapes links", func() {
+		var buf bytes.Buffer
+		err := WriteSVG(&buf, node, 600, 400, SVGOptions{
+			Href: func(image.Image) string { return `"><script>` },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Contains(buf.String(), "<script>")).To(BeFalse())
+	})
+})
@@ -0,0 +1,154 @@
+package picasso
+
+// MirrorHorizontally returns the tree mirrored left to right, e.g. with the pictures that were on the left side of a
+// VerticalSplit moved to the right side. Only the structure of the tree is changed, so the pictures themselves aren't
+// mirrored. Custom Node implementations are kept as they are.
+func MirrorHorizontally(n Node) Node {
+	return Map(n, func(n Node) Node {
+		switch n := n.(type) {
+		case VerticalSplit:
+			return VerticalSplit{Left: n.Right, Right: n.Left, Ratio: 1 / n.Ratio, LeftSize: n.RightSize, RightSize: n.LeftSize}
+		case Grid:
+			return n.mirrored()
+		case Canvas:
+			return n.mapItems(func(item CanvasItem) CanvasItem {
+				item.X, item.Rotation = 1-item.X, -item.Rotation
+				return item
+			})
+		}
+		return n
+	})
+}
+
+// FlipVertically returns the tree flipped top to bottom, e.g. with the hero picture of the TopHeavyLayout at the
+// bottom. Only the structure of the tree is changed, so the pictures themselves remain upright. Custom Node
+// implementations are kept as they are.
+func FlipVertically(n Node) Node {
+	return Map(n, func(n Node) Node {
+		switch n := n.(type) {
+		case HorizontalSplit:
+			return HorizontalSplit{Top: n.Bottom, Bottom: n.Top, Ratio: 1 / n.Ratio, TopSize: n.BottomSize, BottomSize: n.TopSize}
+		case Grid:
+			return n.flipped()
+		case Canvas:
+			return n.mapItems(func(item CanvasItem) CanvasItem {
+				item.Y, item.Rotation = 1-item.Y, -item.Rotation
+				return item
+			})
+		}
+		return n
+	})
+}
+
+// RotateClockwise returns the tree rotated by 90 degrees clockwise, which turns every VerticalSplit into a
+// HorizontalSplit and vice versa. Only the structure of the tree is changed, so the pictures themselves remain
+// upright. Custom Node implementations are kept as they are.
+func RotateClockwise(n Node) Node {
+	return Map(n, func(n Node) Node {
+		switch n := n.(type) {
+		case VerticalSplit:
+			return HorizontalSplit{Top: n.Left, Bottom: n.Right, Ratio: n.Ratio, TopSize: n.LeftSize, BottomSize: n.RightSize}
+		case HorizontalSplit:
+			return VerticalSplit{Left: n.Bottom, Right: n.Top, Ratio: 1 / n.Ratio, LeftSize: n.BottomSize, RightSize: n.TopSize}
+		case Grid:
+			return n.transposed().mirrored()
+		case Canvas:
+			return n.mapItems(func(item CanvasItem) CanvasItem {
+				item.X, item.Y = 1-item.Y, item.X
+				item.Width, item.Height = item.Height, item.Width
+				return item
+			})
+		}
+		return n
+	})
+}
+
+// RotateCounterClockwise returns the tree rotated by 90 degrees counter-clockwise, see RotateClockwise.
+func RotateCounterClockwise(n Node) Node {
+	return Map(n, func(n Node) Node {
+		switch n := n.(type) {
+		case VerticalSplit:
+			return HorizontalSplit{Top: n.Right, Bottom: n.Left, Ratio: 1 / n.Ratio, TopSize: n.RightSize, BottomSize: n.LeftSize}
+		case HorizontalSplit:
+			return VerticalSplit{Left: n.Top, Right: n.Bottom, Ratio: n.Ratio, LeftSize: n.TopSize, RightSize: n.BottomSize}
+		case Grid:
+			return n.transposed().flipped()
+		case Canvas:
+			return n.mapItems(func(item CanvasItem) CanvasItem {
+				item.X, item.Y = item.Y, 1-item.X
+				item.Width, item.Height = item.Height, item.Width
+				return item
+			})
+		}
+		return n
+	})
+}
+
+// Transpose returns the tree mirrored along its diagonal from the top left to the bottom right corner, which turns
+// every VerticalSplit into a HorizontalSplit and vice versa while keeping the first child of every split first.
+func Transpose(n Node) Node {
+	return Map(n, func(n Node) Node {
+		switch n := n.(type) {
+		case VerticalSplit:
+			return HorizontalSplit{Top: n.Left, Bottom: n.Right, Ratio: n.Ratio, TopSize: n.LeftSize, BottomSize: n.RightSize}
+		case HorizontalSplit:
+			return VerticalSplit{Left: n.Top, Right: n.Bottom, Ratio: n.Ratio, LeftSize: n.TopSize, RightSize: n.BottomSize}
+		case Grid:
+			return n.transposed()
+		case Canvas:
+			return n.mapItems(func(item CanvasItem) CanvasItem {
+				item.X, item.Y = item.Y, item.X
+				item.Width, item.Height = item.Height, item.Width
+				item.Rotation = -item.Rotation
+				return item
+			})
+		}
+		return n
+	})
+}
+
+// mirrored returns the grid with the order of its columns reversed
+func (n Grid) mirrored() Grid {
+	cells := make([]GridCell, len(n.Cells))
+	for i, c := range n.Cells {
+		c.Column = len(n.Columns) - (c.Column + c.columnSpan())
+		cells[i] = c
+	}
+	return Grid{Rows: n.Rows, Columns: reversedTracks(n.Columns), Cells: cells}
+}
+
+// flipped returns the grid with the order of its rows reversed
+func (n Grid) flipped() Grid {
+	cells := make([]GridCell, len(n.Cells))
+	for i, c := range n.Cells {
+		c.Row = len(n.Rows) - (c.Row + c.rowSpan())
+		cells[i] = c
+	}
+	return Grid{Rows: reversedTracks(n.Rows), Columns: n.Columns, Cells: cells}
+}
+
+// transposed returns the grid with its rows and columns swapped
+func (n Grid) transposed() Grid {
+	cells := make([]GridCell, len(n.Cells))
+	for i, c := range n.Cells {
+		cells[i] = GridCell{Node: c.Node, Row: c.Column, Column: c.Row, RowSpan: c.ColumnSpan, ColumnSpan: c.RowSpan}
+	}
+	return Grid{Rows: n.Columns, Columns: n.Rows, Cells: cells}
+}
+
+func reversedTracks(tracks []Track) []Track {
+	reversed := make([]Track, len(tracks))
+	for i, t := range tracks {
+		reversed[len(tracks)-1-i] = t
+	}
+	return reversed
+}
+
+// mapItems returns the canvas with every item replaced with what f returns for it
+func (n Canvas) mapItems(f func(CanvasItem) CanvasItem) Canvas {
+	items := make([]CanvasItem, len(n.Items))
+	for i, item := range n.Items {
+		items[i] = f(item)
+	}
+	return Canvas{Items: items, Background: n.Background}
+}
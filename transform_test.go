@@ -0,0 +1,140 @@
+package picasso_test
+
+import (
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+	"github.com/disintegration/gift"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transformations", func() {
+	var (
+		a, b, c, d Picture
+		node       Node
+	)
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(10, 10, color.RGBA{0xff, 0x00, 0x00, 0xff})}
+		b = Picture{uniformImage(10, 10, color.RGBA{0x00, 0xff, 0x00, 0xff})}
+		c = Picture{uniformImage(10, 10, color.RGBA{0x00, 0x00, 0xff, 0xff})}
+		d = Picture{uniformImage(10, 10, color.RGBA{0xff, 0xff, 0x00, 0xff})}
+		// a
+		// --------
+		// b | c d
+		node = HorizontalSplit{
+			Ratio: 2,
+			Top:   a,
+			Bottom: VerticalSplit{
+				Ratio: 0.5,
+				Left:  b,
+				Right: VerticalSplit{Ratio: 1, Left: c, Right: d},
+			},
+		}
+	})
+
+	It("mirrors trees horizontally", func() {
+		Expect(MirrorHorizontally(node)).To(Equal(HorizontalSplit{
+			Ratio: 2,
+			Top:   a,
+			Bottom: VerticalSplit{
+				Ratio: 2,
+				Left:  VerticalSplit{Ratio: 1, Left: d, Right: c},
+				Right: b,
+			},
+		}))
+	})
+
+	It("flips trees vertically", func() {
+		Expect(FlipVertically(node)).To(Equal(HorizontalSplit{
+			Ratio: 0.5,
+			Top: VerticalSplit{
+				Ratio: 0.5,
+				Left:  b,
+				Right: VerticalSplit{Ratio: 1, Left: c, Right: d},
+			},
+			Bottom: a,
+		}))
+	})
+
+	It("rotates trees clockwise", func() {
+		Expect(RotateClockwise(node)).To(Equal(VerticalSplit{
+			Ratio: 0.5,
+			Left: HorizontalSplit{
+				Ratio:  0.5,
+				Top:    b,
+				Bottom: HorizontalSplit{Ratio: 1, Top: c, Bottom: d},
+			},
+			Right: a,
+		}))
+	})
+
+	It("rotates trees counter-clockwise", func() {
+		Expect(RotateCounterClockwise(node)).To(Equal(VerticalSplit{
+			Ratio: 2,
+			Left:  a,
+			Right: HorizontalSplit{
+				Ratio:  2,
+				Top:    HorizontalSplit{Ratio: 1, Top: d, Bottom: c},
+				Bottom: b,
+			},
+		}))
+	})
+
+	It("transposes trees", func() {
+		Expect(Transpose(node)).To(Equal(VerticalSplit{
+			Ratio: 2,
+			Left:  a,
+			Right: HorizontalSplit{
+				Ratio:  0.5,
+				Top:    b,
+				Bottom: HorizontalSplit{Ratio: 1, Top: c, Bottom: d},
+			},
+		}))
+	})
+
+	It("restores the original tree with inverse transformations", func() {
+		Expect(MirrorHorizontally(MirrorHorizontally(node))).To(Equal(node))
+		Expect(FlipVertically(FlipVertically(node))).To(Equal(node))
+		Expect(RotateCounterClockwise(RotateClockwise(node))).To(Equal(node))
+		Expect(RotateClockwise(RotateClockwise(RotateClockwise(RotateClockwise(node))))).To(Equal(node))
+		Expect(Transpose(Transpose(node))).To(Equal(node))
+	})
+
+	It("rotates twice like mirroring and flipping", func() {
+		Expect(RotateClockwise(RotateClockwise(node))).To(Equal(MirrorHorizontally(FlipVertically(node))))
+	})
+
+	It("places the pictures where a rotated image would have them", func() {
+		rotated := RotateClockwise(node)
+		placements := Placements(node, 300, 200, 0)
+		rotatedPlacements := Placements(rotated, 200, 300, 0)
+		for _, p := range placements {
+			for _, r := range rotatedPlacements {
+				if r.Picture != p.Picture {
+					continue
+				}
+				// (x, y) is rotated to (height - y, x)
+				Expect(r.Rect.Dx()).To(BeNumerically("~", p.Rect.Dy(), 1))
+				Expect(r.Rect.Dy()).To(BeNumerically("~", p.Rect.Dx(), 1))
+				Expect(r.Rect.Min.X).To(BeNumerically("~", 200-p.Rect.Max.Y, 1))
+				Expect(r.Rect.Min.Y).To(BeNumerically("~", p.Rect.Min.X, 1))
+			}
+		}
+	})
+
+	It("transforms the nodes within wrappers", func() {
+		filters := []gift.Filter{gift.Grayscale()}
+		wrapped := Filtered{Node: VerticalSplit{Ratio: 1, Left: a, Right: b}, Filters: filters}
+		Expect(MirrorHorizontally(wrapped)).To(Equal(Filtered{
+			Node:    VerticalSplit{Ratio: 1, Left: b, Right: a},
+			Filters: filters,
+		}))
+	})
+
+	It("keeps pictures as they are", func() {
+		Expect(RotateClockwise(a)).To(Equal(a))
+	})
+})
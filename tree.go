@@ -0,0 +1,192 @@
+package picasso
+
+import (
+	"fmt"
+	"image"
+)
+
+// Children returns the child nodes of the node. Splits have two children, the Left and the Right or the Top and the
+// Bottom node, wrapper nodes such as Captioned, Filtered, Watermark and AspectLocked have the node they wrap, grids
+// have the nodes of their cells and canvases have the nodes of their items in the order that they're drawn in.
+// Pictures and custom Node implementations have no children. Children that are nil are included.
+func Children(n Node) []Node {
+	switch n := n.(type) {
+	case VerticalSplit:
+		return []Node{n.Left, n.Right}
+	case HorizontalSplit:
+		return []Node{n.Top, n.Bottom}
+	case Captioned:
+		return []Node{n.Node}
+	case Filtered:
+		return []Node{n.Node}
+	case Watermark:
+		return []Node{n.Node}
+	case AspectLocked:
+		return []Node{n.Node}
+	case Grid:
+		children := make([]Node, len(n.Cells))
+		for i, c := range n.Cells {
+			children[i] = c.Node
+		}
+		return children
+	case Canvas:
+		order := n.drawOrder()
+		children := make([]Node, len(order))
+		for i, item := range order {
+			children[i] = n.Items[item].Node
+		}
+		return children
+	}
+	return nil
+}
+
+// childNames returns the names of the fields that hold the children of the node, in the same order that Children
+// returns the children
+func childNames(n Node) []string {
+	switch n := n.(type) {
+	case VerticalSplit:
+		return []string{"Left", "Right"}
+	case HorizontalSplit:
+		return []string{"Top", "Bottom"}
+	case Captioned, Filtered, Watermark, AspectLocked:
+		return []string{"Node"}
+	case Grid:
+		names := make([]string, len(n.Cells))
+		for i := range n.Cells {
+			names[i] = fmt.Sprintf("Cells[%d].Node", i)
+		}
+		return names
+	case Canvas:
+		order := n.drawOrder()
+		names := make([]string, len(order))
+		for i, item := range order {
+			names[i] = fmt.Sprintf("Items[%d].Node", item)
+		}
+		return names
+	}
+	return nil
+}
+
+// withChildren returns a copy of the node with its children replaced, in the same order that Children returns them
+func withChildren(n Node, children []Node) Node {
+	switch n := n.(type) {
+	case VerticalSplit:
+		n.Left, n.Right = children[0], children[1]
+		return n
+	case HorizontalSplit:
+		n.Top, n.Bottom = children[0], children[1]
+		return n
+	case Captioned:
+		n.Node = children[0]
+		return n
+	case Filtered:
+		n.Node = children[0]
+		return n
+	case Watermark:
+		n.Node = children[0]
+		return n
+	case AspectLocked:
+		n.Node = children[0]
+		return n
+	case Grid:
+		// Copy the cells, so that the cells of the original node wouldn't be modified
+		cells := make([]GridCell, len(n.Cells))
+		for i, c := range n.Cells {
+			c.Node = children[i]
+			cells[i] = c
+		}
+		n.Cells = cells
+		return n
+	case Canvas:
+		// Copy the items, so that the items of the original node wouldn't be modified
+		items := make([]CanvasItem, len(n.Items))
+		copy(items, n.Items)
+		for i, item := range n.drawOrder() {
+			items[item].Node = children[i]
+		}
+		n.Items = items
+		return n
+	}
+	return n
+}
+
+// childRects returns the rectangles that the children of the node are drawn into when the node itself is drawn into
+// rect, in the same order that Children returns the children. The rectangles include the borders of the children.
+func childRects(n Node, rect image.Rectangle, borderWidth int) []image.Rectangle {
+	switch n := n.(type) {
+	case VerticalSplit:
+		leftRect, rightRect := n.childRects(rect.Dx(), rect.Dy(), borderWidth)
+		return []image.Rectangle{leftRect.Add(rect.Min), rightRect.Add(rect.Min)}
+	case HorizontalSplit:
+		topRect, bottomRect := n.childRects(rect.Dx(), rect.Dy(), borderWidth)
+		return []image.Rectangle{topRect.Add(rect.Min), bottomRect.Add(rect.Min)}
+	case Captioned:
+		nodeRect, _ := n.rects(rect.Dx(), rect.Dy(), borderWidth)
+		return []image.Rectangle{nodeRect.Add(rect.Min)}
+	case Filtered, Watermark:
+		return []image.Rectangle{rect}
+	case AspectLocked:
+		return []image.Rectangle{n.nodeRect(rect.Dx(), rect.Dy(), borderWidth).Add(rect.Min)}
+	case Grid:
+		rects := n.cellRects(rect.Dx(), rect.Dy(), borderWidth)
+		for i := range rects {
+			rects[i] = rects[i].Add(rect.Min)
+		}
+		return rects
+	case Canvas:
+		order := n.drawOrder()
+		rects := make([]image.Rectangle, len(order))
+		for i, item := range order {
+			itemRect := n.Items[item].itemRect(rect.Dx(), rect.Dy()).Add(rect.Min)
+			rects[i] = n.Items[item].nodeRect(itemRect.Dx(), itemRect.Dy()).Add(itemRect.Min)
+		}
+		return rects
+	}
+	return nil
+}
+
+// Visit calls visit for the node and all of its descendants in depth-first order, parents before their children.
+// The children of a node are skipped if visit returns false for it. Nil nodes are not visited.
+func Visit(n Node, visit func(Node) bool) {
+	if n == nil || !visit(n) {
+		return
+	}
+	for _, child := range Children(n) {
+		Visit(child, visit)
+	}
+}
+
+// Walk is like Visit, but it also calculates the rectangle that every node is drawn into when the tree is drawn at
+// the given size. A borderWidth of 0 gives the rectangles of Draw and any other width the rectangles of
+// DrawWithBorder, with the rectangles including the borders of the nodes.
+func Walk(n Node, width, height, borderWidth int, visit func(n Node, rect image.Rectangle) bool) {
+	walk(n, image.Rect(0, 0, width, height), borderWidth, visit)
+}
+
+func walk(n Node, rect image.Rectangle, borderWidth int, visit func(n Node, rect image.Rectangle) bool) {
+	if n == nil || !visit(n, rect) {
+		return
+	}
+	rects := childRects(n, rect, borderWidth)
+	for i, child := range Children(n) {
+		walk(child, rects[i], borderWidth, visit)
+	}
+}
+
+// Map rebuilds the tree from the bottom up by calling f for every node after its children have already been
+// replaced with what f returned for them. Returning the node itself keeps it as it is. Nil nodes are kept as they
+// are without calling f.
+func Map(n Node, f func(Node) Node) Node {
+	if n == nil {
+		return nil
+	}
+	children := Children(n)
+	if len(children) > 0 {
+		mapped := make([]Node, len(children))
+		for i, child := range children {
+			mapped[i] = Map(child, f)
+		}
+		n = withChildren(n, mapped)
+	}
+	return f(n)
+}
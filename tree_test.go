@@ -0,0 +1,153 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tree", func() {
+	var (
+		a, b, c Picture
+		bottom  VerticalSplit
+		node    HorizontalSplit
+	)
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(10, 10, color.RGBA{0xff, 0x00, 0x00, 0xff})}
+		b = Picture{uniformImage(10, 10, color.RGBA{0x00, 0xff, 0x00, 0xff})}
+		c = Picture{uniformImage(10, 10, color.RGBA{0x00, 0x00, 0xff, 0xff})}
+		bottom = VerticalSplit{Ratio: 1, Left: b, Right: c}
+		node = HorizontalSplit{Ratio: 1, Top: a, Bottom: bottom}
+	})
+
+	Describe("Children", func() {
+		It("returns the children of all the node types", func() {
+			Expect(Children(node)).To(Equal([]Node{a, bottom}))
+			Expect(Children(bottom)).To(Equal([]Node{b, c}))
+			Expect(Children(Filtered{Node: a})).To(Equal([]Node{a}))
+			Expect(Children(Watermark{Node: a})).To(Equal([]Node{a}))
+			Expect(Children(Captioned{Node: a})).To(Equal([]Node{a}))
+			Expect(Children(a)).To(BeEmpty())
+			Expect(Children(customNode{})).To(BeEmpty())
+		})
+
+		It("includes nil children", func() {
+			Expect(Children(VerticalSplit{Left: a})).To(Equal([]Node{a, nil}))
+		})
+	})
+
+	Describe("Visit", func() {
+		It("visits parents before their children", func() {
+			var visited []Node
+			Visit(node, func(n Node) bool {
+				visited = append(visited, n)
+				return true
+			})
+			Expect(visited).To(Equal([]Node{node, a, bottom, b, c}))
+		})
+
+		It("skips the children of nodes that visit returns false for", func() {
+			var visited []Node
+			Visit(node, func(n Node) bool {
+				visited = append(visited, n)
+				_, isSplit := n.(VerticalSplit)
+				return !isSplit
+			})
+			Expect(visited).To(Equal([]Node{node, a, bottom}))
+		})
+
+		It("doesn't visit nil nodes", func() {
+			var visited []Node
+			Visit(VerticalSplit{Left: a}, func(n Node) bool {
+				visited = append(visited, n)
+				return true
+			})
+			Expect(visited).To(HaveLen(2))
+		})
+	})
+
+	Describe("Walk", func() {
+		It("calculates the rectangles of all the nodes", func() {
+			rects := map[Node]image.Rectangle{}
+			Walk(node, 100, 60, 0, func(n Node, rect image.Rectangle) bool {
+				if p, ok := n.(Picture); ok {
+					rects[p] = rect
+				}
+				return true
+			})
+			Expect(rects).To(Equal(map[Node]image.Rectangle{
+				a: image.Rect(0, 0, 100, 30),
+				b: image.Rect(0, 30, 50, 60),
+				c: image.Rect(50, 30, 100, 60),
+			}))
+		})
+
+		It("includes the overlapping borders in the rectangles", func() {
+			var rects []image.Rectangle
+			Walk(node, 100, 60, 2, func(n Node, rect image.Rectangle) bool {
+				rects = append(rects, rect)
+				return true
+			})
+			Expect(rects).To(Equal([]image.Rectangle{
+				image.Rect(0, 0, 100, 60),
+				image.Rect(0, 0, 100, 31),
+				image.Rect(0, 29, 100, 60),
+				image.Rect(0, 29, 51, 60),
+				image.Rect(49, 29, 100, 60),
+			}))
+		})
+
+		It("agrees with the placements", func() {
+			var rects []image.Rectangle
+			Walk(node, 100, 60, 2, func(n Node, rect image.Rectangle) bool {
+				if _, ok := n.(Picture); ok {
+					rects = append(rects, rect)
+				}
+				return true
+			})
+			for i, p := range Placements(node, 100, 60, 2) {
+				Expect(p.Rect).To(Equal(rects[i].Inset(2)))
+			}
+		})
+	})
+
+	Describe("Map", func() {
+		It("replaces leaves", func() {
+			mapped := Map(node, func(n Node) Node {
+				if n == b {
+					return customNode{}
+				}
+				return n
+			})
+			Expect(mapped).To(Equal(HorizontalSplit{
+				Ratio:  1,
+				Top:    a,
+				Bottom: VerticalSplit{Ratio: 1, Left: customNode{}, Right: c},
+			}))
+		})
+
+		It("passes nodes with their children already replaced", func() {
+			mapped := Map(node, func(n Node) Node {
+				switch n := n.(type) {
+				case Picture:
+					return Filtered{Node: n}
+				case VerticalSplit:
+					Expect(n.Left).To(Equal(Filtered{Node: b}))
+					return n
+				}
+				return n
+			})
+			Expect(mapped.(HorizontalSplit).Top).To(Equal(Filtered{Node: a}))
+		})
+
+		It("keeps nil nodes", func() {
+			Expect(Map(VerticalSplit{Left: a}, func(n Node) Node { return n })).To(Equal(VerticalSplit{Left: a}))
+			Expect(Map(nil, func(n Node) Node { return a })).To(BeNil())
+		})
+	})
+})
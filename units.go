@@ -0,0 +1,147 @@
+package picasso
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Length is a physical length, stored in PostScript points, i.e. in 1/72ths of an inch. Use the constants to
+// specify lengths in other units, e.g. 210 * Millimetre.
+type Length float64
+
+// Commonly used units of length.
+const (
+	Point      Length = 1
+	Inch       Length = 72
+	Millimetre Length = Inch / 25.4
+	Centimetre Length = 10 * Millimetre
+)
+
+// Pixels returns the number of whole pixels that the length spans at the given resolution.
+func (l Length) Pixels(dpi float64) int {
+	return int(math.Floor(float64(l)/float64(Inch)*dpi + 0.5))
+}
+
+// PrintOptions configures DrawForPrint.
+type PrintOptions struct {
+	// Size is the physical size of the composed image.
+	Size PaperSize
+	// DPI is the resolution that the composed image is drawn at.
+	DPI float64
+	// BorderColor and BorderWidth have the same meaning as the arguments of DrawWithBorder. Borders are only drawn
+	// if BorderWidth spans at least a pixel.
+	BorderColor color.Color
+	BorderWidth Length
+	// MaxUpsampling is the largest factor that a picture can be enlarged by without a warning being reported. A
+	// value of 1 warns about any enlargement. Zero disables the warnings.
+	MaxUpsampling float64
+}
+
+// UpsamplingWarning reports a picture that has to be enlarged more than PrintOptions.MaxUpsampling allows.
+type UpsamplingWarning struct {
+	Placement Placement
+	// Scale is the factor that the picture is enlarged by.
+	Scale float64
+}
+
+func (w UpsamplingWarning) String() string {
+	return fmt.Sprintf("picture %d is enlarged %.2f times to fill %dx%d pixels", w.Placement.Index, w.Scale,
+		w.Placement.Rect.Dx(), w.Placement.Rect.Dy())
+}
+
+// DrawForPrint draws the node at the physical size and resolution given in the options. It also returns warnings for
+// every picture that is enlarged more than the options allow. Encode the result with EncodePNG or EncodeJPEG to keep
+// the resolution in the file's metadata.
+func DrawForPrint(n Node, o PrintOptions) (image.Image, []UpsamplingWarning) {
+	width, height := o.Size.Width.Pixels(o.DPI), o.Size.Height.Pixels(o.DPI)
+	borderWidth := o.BorderWidth.Pixels(o.DPI)
+
+	var warnings []UpsamplingWarning
+	if o.MaxUpsampling > 0 {
+		for _, r := range AnalyzeResolution(n, width, height, borderWidth) {
+			if r.Scale > o.MaxUpsampling {
+				warnings = append(warnings, UpsamplingWarning{r.Placement, r.Scale})
+			}
+		}
+	}
+
+	if borderWidth > 0 {
+		return n.DrawWithBorder(width, height, o.BorderColor, borderWidth), warnings
+	}
+	return n.Draw(width, height), warnings
+}
+
+// EncodePNG encodes the image as a PNG just like png.Encode does, but also records the given resolution in a pHYs
+// chunk.
+func EncodePNG(w io.Writer, m image.Image, dpi float64) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		return err
+	}
+	encoded := buf.Bytes()
+	// The 8 byte signature is always followed by the IHDR chunk, which consists of a 4 byte length, a 4 byte type, 13
+	// bytes of data and a 4 byte CRC. The pHYs chunk has to come before the image data, so right after IHDR is fine.
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+
+	pixelsPerMetre := uint32(dpi/0.0254 + 0.5)
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], pixelsPerMetre)
+	binary.BigEndian.PutUint32(chunk[12:], pixelsPerMetre)
+	chunk[16] = 1 // the unit is the metre
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	if _, err := w.Write(encoded[:ihdrEnd]); err != nil {
+		return err
+	}
+	if _, err := w.Write(chunk); err != nil {
+		return err
+	}
+	_, err := w.Write(encoded[ihdrEnd:])
+	return err
+}
+
+// EncodeJPEG encodes the image as a JPEG just like jpeg.Encode does, but also records the given resolution in a JFIF
+// APP0 segment.
+func EncodeJPEG(w io.Writer, m image.Image, o *jpeg.Options, dpi float64) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, m, o); err != nil {
+		return err
+	}
+	encoded := buf.Bytes()
+
+	density := uint16(math.Min(dpi+0.5, math.MaxUint16))
+	app0 := []byte{
+		0xff, 0xe0, // APP0 marker
+		0x00, 0x10, // segment length
+		'J', 'F', 'I', 'F', 0x00,
+		0x01, 0x02, // version 1.02
+		0x01,                              // the unit is the inch
+		byte(density >> 8), byte(density), // horizontal density
+		byte(density >> 8), byte(density), // vertical density
+		0x00, 0x00, // no thumbnail
+	}
+
+	// Replace the existing JFIF segment, if the encoder wrote one, or insert a new one right after the SOI marker
+	rest := encoded[2:]
+	if len(rest) >= 18 && rest[0] == 0xff && rest[1] == 0xe0 && bytes.Equal(rest[4:9], []byte("JFIF\x00")) {
+		rest = rest[2+int(binary.BigEndian.Uint16(rest[2:])):]
+	}
+	if _, err := w.Write(encoded[:2]); err != nil {
+		return err
+	}
+	if _, err := w.Write(app0); err != nil {
+		return err
+	}
+	_, err := w.Write(rest)
+	return err
+}
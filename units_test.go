@@ -0,0 +1,111 @@
+package picasso_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Units", func() {
+	Describe("Length", func() {
+		It("converts to pixels", func() {
+			Expect((1 * Inch).Pixels(300)).To(Equal(300))
+			Expect((72 * Point).Pixels(150)).To(Equal(150))
+			Expect((25.4 * Millimetre).Pixels(96)).To(Equal(96))
+			Expect((210 * Millimetre).Pixels(300)).To(Equal(2480))
+			Expect((297 * Millimetre).Pixels(300)).To(Equal(3508))
+		})
+	})
+
+	Describe("DrawForPrint", func() {
+		var (
+			red  = color.RGBA{0xff, 0x00, 0x00, 0xff}
+			blue = color.RGBA{0x00, 0x00, 0xff, 0xff}
+			node Node
+		)
+
+		BeforeEach(func() {
+			node = VerticalSplit{
+				Ratio: 1,
+				Left:  Picture{uniformImage(400, 400, red)},
+				Right: Picture{uniformImage(100, 100, blue)},
+			}
+		})
+
+		It("draws at the physical size", func() {
+			i, warnings := DrawForPrint(node, PrintOptions{
+				Size: PaperSize{4 * Inch, 2 * Inch},
+				DPI:  100,
+			})
+			Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 400, 200)))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("converts the border width", func() {
+			i, _ := DrawForPrint(node, PrintOptions{
+				Size:        PaperSize{4 * Inch, 2 * Inch},
+				DPI:         144,
+				BorderColor: color.White,
+				BorderWidth: 2 * Point,
+			})
+			Expect(i.Bounds()).To(Equal(image.Rect(0, 0, 576, 288)))
+			Expect(i.At(3, 3)).To(Equal(color.RGBA{0xff, 0xff, 0xff, 0xff}))
+			Expect(i.At(4, 4)).To(Equal(red))
+		})
+
+		It("warns about enlarged pictures", func() {
+			_, warnings := DrawForPrint(node, PrintOptions{
+				Size:          PaperSize{4 * Inch, 2 * Inch},
+				DPI:           100,
+				MaxUpsampling: 1.5,
+			})
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0].Placement.Index).To(Equal(1))
+			Expect(warnings[0].Scale).To(BeNumerically("~", 2))
+			Expect(warnings[0].String()).To(Equal("picture 1 is enlarged 2.00 times to fill 200x200 pixels"))
+		})
+	})
+
+	Describe("EncodePNG", func() {
+		It("records the resolution", func() {
+			var buf bytes.Buffer
+			Expect(EncodePNG(&buf, uniformImage(10, 10, color.White), 300)).To(Succeed())
+
+			data := buf.Bytes()
+			index := bytes.Index(data, []byte("pHYs"))
+			Expect(index).To(BeNumerically(">", 0))
+			Expect(binary.BigEndian.Uint32(data[index+4:])).To(Equal(uint32(11811)))
+			Expect(binary.BigEndian.Uint32(data[index+8:])).To(Equal(uint32(11811)))
+			Expect(data[index+12]).To(Equal(byte(1)))
+
+			_, err := png.Decode(&buf)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("EncodeJPEG", func() {
+		It("records the resolution", func() {
+			var buf bytes.Buffer
+			Expect(EncodeJPEG(&buf, uniformImage(10, 10, color.White), nil, 300)).To(Succeed())
+
+			data := buf.Bytes()
+			Expect(data[:4]).To(Equal([]byte{0xff, 0xd8, 0xff, 0xe0}))
+			Expect(data[6:11]).To(Equal([]byte("JFIF\x00")))
+			Expect(data[13]).To(Equal(byte(1)))
+			Expect(binary.BigEndian.Uint16(data[14:])).To(Equal(uint16(300)))
+			Expect(binary.BigEndian.Uint16(data[16:])).To(Equal(uint16(300)))
+			Expect(bytes.Count(data, []byte("JFIF"))).To(Equal(1))
+
+			_, err := jpeg.Decode(&buf)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
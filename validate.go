@@ -0,0 +1,173 @@
+package picasso
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// NodeProblem describes a problem with a single node of a tree.
+type NodeProblem struct {
+	// Path is the path to the node from the root of the tree, with the names of the fields that hold the nodes
+	// separated by dots, e.g. "Bottom.Left". The path of the root is empty.
+	Path    string
+	Problem string
+}
+
+func (p NodeProblem) String() string {
+	if p.Path == "" {
+		return "root: " + p.Problem
+	}
+	return p.Path + ": " + p.Problem
+}
+
+// ValidationError lists all the problems found in a tree.
+type ValidationError struct {
+	Problems []NodeProblem
+}
+
+func (e ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return "invalid tree: " + strings.Join(problems, "; ")
+}
+
+// Validate checks the tree for problems that would make drawing it fail or produce empty cells: missing nodes, such as
+// a VerticalSplit with a nil Left node, splits and AspectLocked nodes with ratios that aren't positive numbers, splits
+// with size constraints that can't be satisfied, grids with invalid tracks or cells outside of the grid, canvas items
+// without a size and pictures without images. The ratio of a split isn't checked if a child of the split has a Fixed
+// size, because the ratio isn't used then.
+// All the problems are reported in a ValidationError. Custom Node implementations are not checked.
+func Validate(n Node) error {
+	var problems []NodeProblem
+	var validate func(n Node, path string)
+	validate = func(n Node, path string) {
+		report := func(format string, args ...interface{}) {
+			problems = append(problems, NodeProblem{path, fmt.Sprintf(format, args...)})
+		}
+		switch n := n.(type) {
+		case nil:
+			report("missing node")
+			return
+		case Picture:
+			if n.Picture == nil {
+				report("missing image")
+			} else if n.Picture.Bounds().Empty() {
+				report("empty image")
+			}
+		case VerticalSplit:
+			if !validRatio(n.Ratio) && !overridesRatio(n.LeftSize, n.RightSize) {
+				report("invalid ratio %v", n.Ratio)
+			}
+			if !n.LeftSize.valid() {
+				report("invalid LeftSize %v", n.LeftSize)
+			}
+			if !n.RightSize.valid() {
+				report("invalid RightSize %v", n.RightSize)
+			}
+		case HorizontalSplit:
+			if !validRatio(n.Ratio) && !overridesRatio(n.TopSize, n.BottomSize) {
+				report("invalid ratio %v", n.Ratio)
+			}
+			if !n.TopSize.valid() {
+				report("invalid TopSize %v", n.TopSize)
+			}
+			if !n.BottomSize.valid() {
+				report("invalid BottomSize %v", n.BottomSize)
+			}
+		case AspectLocked:
+			if !validRatio(n.AspectRatio) {
+				report("invalid aspect ratio %v", n.AspectRatio)
+			}
+		case Grid:
+			for i, t := range n.Rows {
+				if !t.valid() {
+					report("invalid Rows[%d] %+v", i, t)
+				}
+			}
+			for i, t := range n.Columns {
+				if !t.valid() {
+					report("invalid Columns[%d] %+v", i, t)
+				}
+			}
+			for i, c := range n.Cells {
+				if c.Row < 0 || c.Column < 0 || c.Row+c.rowSpan() > len(n.Rows) || c.Column+c.columnSpan() > len(n.Columns) {
+					report("Cells[%d] not within the grid", i)
+				}
+			}
+		case Canvas:
+			for i, item := range n.Items {
+				if !(item.Width > 0 && item.Height > 0) {
+					report("invalid size of Items[%d] %gx%g", i, item.Width, item.Height)
+				}
+			}
+		}
+		names := childNames(n)
+		for i, child := range Children(n) {
+			childPath := names[i]
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			validate(child, childPath)
+		}
+	}
+	validate(n, "")
+	if len(problems) > 0 {
+		return ValidationError{problems}
+	}
+	return nil
+}
+
+func validRatio(r float32) bool {
+	return r > 0 && !math.IsInf(float64(r), 0)
+}
+
+// Normalize removes missing nodes from the tree by replacing every split that has a single child with that child.
+// Splits without any children and wrapper nodes, such as Filtered, without a wrapped node are removed as well, as are
+// the cells of grids and the items of canvases without a node and grids and canvases without any, so nil is returned
+// for a tree without any nodes. The ratios of the splits are not changed, so Validate can still report problems with
+// the normalized tree.
+func Normalize(n Node) Node {
+	return Map(n, func(n Node) Node {
+		children := Children(n)
+		switch n := n.(type) {
+		case VerticalSplit, HorizontalSplit:
+			if children[0] == nil {
+				return children[1]
+			} else if children[1] == nil {
+				return children[0]
+			}
+		case Captioned, Filtered, Watermark, AspectLocked:
+			if children[0] == nil {
+				return nil
+			}
+		case Grid:
+			var cells []GridCell
+			for _, c := range n.Cells {
+				if c.Node != nil {
+					cells = append(cells, c)
+				}
+			}
+			if len(cells) == 0 {
+				return nil
+			}
+			n.Cells = cells
+			return n
+		case Canvas:
+			var items []CanvasItem
+			for _, item := range n.Items {
+				if item.Node != nil {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			n.Items = items
+			return n
+		}
+		return n
+	})
+}
@@ -0,0 +1,104 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var a, b Picture
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(10, 10, color.RGBA{0xff, 0x00, 0x00, 0xff})}
+		b = Picture{uniformImage(10, 10, color.RGBA{0x00, 0xff, 0x00, 0xff})}
+	})
+
+	It("accepts valid trees", func() {
+		Expect(Validate(HorizontalSplit{Ratio: 1, Top: a, Bottom: VerticalSplit{Ratio: 2, Left: a, Right: b}})).To(Succeed())
+	})
+
+	It("accepts all the trees composed by the layouts", func() {
+		var images []image.Image
+		for i := 0; i < 12; i++ {
+			if i%3 == 0 {
+				images = append(images, uniformImage(10, 20, color.Black))
+			} else {
+				images = append(images, uniformImage(20, 10, color.Black))
+			}
+			Expect(Validate(TopHeavyLayout().Compose(images))).To(Succeed())
+			Expect(Validate(GoldenSpiralLayout().Compose(images))).To(Succeed())
+		}
+	})
+
+	It("reports all the problems with the paths to the nodes", func() {
+		err := Validate(HorizontalSplit{
+			Ratio: 1,
+			Top:   Picture{},
+			Bottom: VerticalSplit{
+				Ratio: -1,
+				Left:  Filtered{},
+				Right: Picture{image.NewRGBA(image.Rect(0, 0, 0, 10))},
+			},
+		})
+		Expect(err).To(Equal(ValidationError{[]NodeProblem{
+			{"Top", "missing image"},
+			{"Bottom", "invalid ratio -1"},
+			{"Bottom.Left.Node", "missing node"},
+			{"Bottom.Right", "empty image"},
+		}}))
+		Expect(err.Error()).To(Equal("invalid tree: Top: missing image; Bottom: invalid ratio -1; " +
+			"Bottom.Left.Node: missing node; Bottom.Right: empty image"))
+	})
+
+	It("reports problems with the root", func() {
+		err := Validate(VerticalSplit{Left: a, Right: b})
+		Expect(err).To(MatchError("invalid tree: root: invalid ratio 0"))
+		Expect(Validate(nil)).To(MatchError("invalid tree: root: missing node"))
+	})
+
+	It("doesn't check custom nodes", func() {
+		Expect(Validate(customNode{})).To(Succeed())
+	})
+})
+
+var _ = Describe("Normalize", func() {
+	var a, b Picture
+
+	BeforeEach(func() {
+		a = Picture{uniformImage(10, 10, color.RGBA{0xff, 0x00, 0x00, 0xff})}
+		b = Picture{uniformImage(10, 10, color.RGBA{0x00, 0xff, 0x00, 0xff})}
+	})
+
+	It("collapses splits with a missing child into the remaining child", func() {
+		Expect(Normalize(HorizontalSplit{
+			Ratio:  1,
+			Top:    VerticalSplit{Ratio: 1, Right: a},
+			Bottom: VerticalSplit{Ratio: 1, Left: b},
+		})).To(Equal(HorizontalSplit{Ratio: 1, Top: a, Bottom: b}))
+	})
+
+	It("collapses nested splits without any pictures", func() {
+		Expect(Normalize(HorizontalSplit{
+			Ratio:  1,
+			Top:    VerticalSplit{Ratio: 1, Left: HorizontalSplit{}, Right: Filtered{}},
+			Bottom: a,
+		})).To(Equal(a))
+		Expect(Normalize(VerticalSplit{})).To(BeNil())
+	})
+
+	It("keeps valid trees as they are", func() {
+		node := HorizontalSplit{Ratio: 1, Top: a, Bottom: Filtered{Node: VerticalSplit{Ratio: 2, Left: a, Right: b}}}
+		Expect(Normalize(node)).To(Equal(node))
+	})
+
+	It("makes trees with missing nodes valid", func() {
+		node := VerticalSplit{Ratio: 1, Left: a, Right: HorizontalSplit{Ratio: 1, Top: b}}
+		Expect(Validate(node)).NotTo(Succeed())
+		Expect(Validate(Normalize(node))).To(Succeed())
+	})
+})
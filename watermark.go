@@ -0,0 +1,169 @@
+package picasso
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/gift"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Anchor is a position within an area that content smaller than the area is attached to.
+type Anchor int
+
+const (
+	CenterAnchor Anchor = iota
+	TopLeftAnchor
+	TopAnchor
+	TopRightAnchor
+	LeftAnchor
+	RightAnchor
+	BottomLeftAnchor
+	BottomAnchor
+	BottomRightAnchor
+)
+
+// position returns the top left corner of content of the given size that is anchored to the given area
+func (a Anchor) position(area image.Rectangle, size image.Point) image.Point {
+	x := area.Min.X + (area.Dx()-size.X)/2
+	switch a {
+	case TopLeftAnchor, LeftAnchor, BottomLeftAnchor:
+		x = area.Min.X
+	case TopRightAnchor, RightAnchor, BottomRightAnchor:
+		x = area.Max.X - size.X
+	}
+	y := area.Min.Y + (area.Dy()-size.Y)/2
+	switch a {
+	case TopLeftAnchor, TopAnchor, TopRightAnchor:
+		y = area.Min.Y
+	case BottomLeftAnchor, BottomAnchor, BottomRightAnchor:
+		y = area.Max.Y - size.Y
+	}
+	return image.Pt(x, y)
+}
+
+// Watermark is a Node that draws the node that it wraps and then overlays it with a logo or a line of text. Wrap the
+// root of a tree to brand the whole collage or wrap single pictures to brand them individually. Like captions,
+// watermarks are only drawn into images.
+type Watermark struct {
+	Node Node
+	// Image is the logo to overlay the node with.
+	Image image.Image
+	// Text is drawn with the Font and the Color if there's no Image. The Color defaults to white.
+	Text  string
+	Font  *Font
+	Color color.Color
+	// Anchor determines the corner, edge or the center of the node that the watermark is placed at.
+	Anchor Anchor
+	// Scale is the width of the watermark relative to the width of the node. Defaults to 0.25.
+	Scale float64
+	// Opacity of the watermark from 0 to 1. Zero is treated as 1, so a nearly invisible watermark needs a small
+	// positive value.
+	Opacity float64
+	// Margin is the space in pixels between the watermark and the edges of the node, borders excluded.
+	Margin int
+}
+
+func (n Watermark) Draw(width, height int) image.Image {
+	return Renderer{}.Draw(n, width, height)
+}
+
+func (n Watermark) DrawWithBorder(width, height int, borderColor color.Color, borderWidth int) image.Image {
+	return Renderer{}.DrawWithBorder(n, width, height, borderColor, borderWidth)
+}
+
+// paintWatermark draws the watermark into the given area of dst, resizing the logo with the resampling of the quality
+func (n Watermark) paintWatermark(dst *image.RGBA, area image.Rectangle, q Quality) {
+	area = insetRect(area, n.Margin)
+	if area.Empty() {
+		return
+	}
+	scale := n.Scale
+	if scale <= 0 {
+		scale = 0.25
+	}
+	width := int(float64(area.Dx())*scale + 0.5)
+	if width <= 0 {
+		return
+	}
+
+	var mark image.Image
+	if n.Image != nil {
+		mark = n.resizeImage(width, q)
+	} else {
+		mark = n.drawText(width)
+	}
+	if mark == nil {
+		return
+	}
+
+	opacity := n.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 1
+	}
+	mask := image.NewUniform(color.Alpha{uint8(opacity*0xff + 0.5)})
+	bounds := mark.Bounds()
+	position := n.Anchor.position(area, bounds.Size())
+	// Clip the watermark to the area, so that a tall logo wouldn't cover the border of the node
+	r := image.Rectangle{Min: position, Max: position.Add(bounds.Size())}.Intersect(area)
+	draw.DrawMask(dst, r, mark, bounds.Min.Add(r.Min.Sub(position)), mask, image.ZP, draw.Over)
+}
+
+func (n Watermark) resizeImage(width int, q Quality) image.Image {
+	logo, err := decodePixels(n.Image)
+	if err != nil {
+		return nil
+	}
+	// A height of 0 keeps the aspect ratio of the logo
+	g := gift.New(
+		gift.Resize(width, 0, q.resampling()),
+	)
+	dst := image.NewRGBA(g.Bounds(logo.Bounds()))
+	g.Draw(dst, logo)
+	return dst
+}
+
+// drawText draws the text in a font size that makes it as wide as requested
+func (n Watermark) drawText(width int) image.Image {
+	if n.Font == nil || n.Text == "" {
+		return nil
+	}
+	// Measure the text at a reference size first to find the size that gives the requested width
+	const referenceSize = 100
+	reference, ok := CaptionStyle{Font: n.Font, Size: referenceSize}.face()
+	if !ok {
+		return nil
+	}
+	referenceWidth := font.MeasureString(reference, n.Text)
+	reference.Close()
+	if referenceWidth <= 0 {
+		return nil
+	}
+	face, err := opentype.NewFace(n.Font.font, &opentype.FaceOptions{
+		Size:    referenceSize * float64(width) / (float64(referenceWidth) / 64),
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	textColor := n.Color
+	if textColor == nil {
+		textColor = color.White
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, (metrics.Ascent + metrics.Descent).Ceil()))
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(textColor),
+		Face: face,
+		Dot:  fixed.P(0, metrics.Ascent.Ceil()),
+	}
+	drawer.DrawString(n.Text)
+	return dst
+}
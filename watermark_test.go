@@ -0,0 +1,119 @@
+package picasso_test
+
+import (
+	"image"
+	"image/color"
+
+	. "github.com/deiwin/picasso"
+	"golang.org/x/image/font/gofont/goregular"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watermark", func() {
+	var (
+		red   = color.RGBA{0xff, 0x00, 0x00, 0xff}
+		white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+		gray  = color.RGBA{0xaf, 0xaf, 0xaf, 0xff}
+		logo  = uniformImage(20, 10, white)
+	)
+
+	It("draws the logo at the bottom right corner with a margin", func() {
+		node := Watermark{
+			Node:   Picture{uniformImage(100, 100, red)},
+			Image:  logo,
+			Anchor: BottomRightAnchor,
+			Scale:  0.2,
+			Margin: 5,
+		}
+		m := node.Draw(100, 100)
+		// The logo is scaled to a fifth of the 90 pixels between the margins
+		Expect(m.At(77, 86)).To(Equal(white))
+		Expect(m.At(94, 94)).To(Equal(white))
+		Expect(m.At(76, 90)).To(Equal(red))
+		Expect(m.At(80, 85)).To(Equal(red))
+		Expect(m.At(95, 95)).To(Equal(red))
+	})
+
+	table.DescribeTable("anchors",
+		func(anchor Anchor, inside, outside image.Point) {
+			m := Watermark{
+				Node:   Picture{uniformImage(100, 100, red)},
+				Image:  logo,
+				Anchor: anchor,
+				Scale:  0.2,
+			}.Draw(100, 100)
+			Expect(m.At(inside.X, inside.Y)).To(Equal(white))
+			Expect(m.At(outside.X, outside.Y)).To(Equal(red))
+		},
+		table.Entry("center", CenterAnchor, image.Pt(40, 45), image.Pt(39, 45)),
+		table.Entry("top left", TopLeftAnchor, image.Pt(0, 0), image.Pt(20, 0)),
+		table.Entry("top", TopAnchor, image.Pt(40, 0), image.Pt(40, 10)),
+		table.Entry("top right", TopRightAnchor, image.Pt(99, 0), image.Pt(79, 0)),
+		table.Entry("left", LeftAnchor, image.Pt(0, 45), image.Pt(0, 44)),
+		table.Entry("right", RightAnchor, image.Pt(99, 54), image.Pt(99, 55)),
+		table.Entry("bottom left", BottomLeftAnchor, image.Pt(0, 99), image.Pt(0, 89)),
+		table.Entry("bottom", BottomAnchor, image.Pt(59, 99), image.Pt(60, 99)),
+		table.Entry("bottom right", BottomRightAnchor, image.Pt(80, 90), image.Pt(79, 90)),
+	)
+
+	It("blends the watermark with the node according to its opacity", func() {
+		m := Watermark{
+			Node:    Picture{uniformImage(100, 100, red)},
+			Image:   logo,
+			Anchor:  TopLeftAnchor,
+			Opacity: 0.5,
+		}.Draw(100, 100)
+		Expect(m.At(0, 0)).To(Equal(color.RGBA{0xff, 0x80, 0x80, 0xff}))
+	})
+
+	It("keeps the watermark within the border", func() {
+		node := Watermark{
+			Node:   Picture{uniformImage(100, 100, red)},
+			Image:  uniformImage(10, 20, white),
+			Anchor: TopLeftAnchor,
+			Scale:  1,
+		}
+		m := node.DrawWithBorder(100, 100, gray, 2)
+		Expect(m.At(1, 1)).To(Equal(gray))
+		Expect(m.At(2, 2)).To(Equal(white))
+		Expect(m.At(97, 97)).To(Equal(white))
+		Expect(m.At(98, 98)).To(Equal(gray))
+		Expect(Placements(node, 100, 100, 2)).To(Equal(Placements(node.Node, 100, 100, 2)))
+	})
+
+	It("draws text in the requested width", func() {
+		font, err := ParseFont(goregular.TTF)
+		Expect(err).NotTo(HaveOccurred())
+		m := Watermark{
+			Node:   Picture{uniformImage(200, 100, red)},
+			Text:   "© Picasso",
+			Font:   font,
+			Anchor: TopLeftAnchor,
+			Scale:  0.5,
+		}.Draw(200, 100)
+		var columns []int
+		for x := 0; x < 200; x++ {
+			for y := 0; y < 100; y++ {
+				if m.At(x, y) == white {
+					columns = append(columns, x)
+					break
+				}
+			}
+		}
+		Expect(columns).NotTo(BeEmpty())
+		Expect(columns[0]).To(BeNumerically("<", 10))
+		Expect(columns[len(columns)-1]).To(BeNumerically("~", 100, 10))
+	})
+
+	It("brands whole collages", func() {
+		node := TopHeavyLayout().Compose([]image.Image{uniformImage(100, 100, red), uniformImage(100, 50, red)})
+		m := Watermark{Node: node, Image: logo, Anchor: BottomRightAnchor}.DrawWithBorder(100, 150, gray, 2)
+		Expect(m.At(97, 147)).To(Equal(white))
+		Expect(m.At(50, 2)).To(Equal(red))
+		Expect(Renderer{}.DrawWithBorder(Watermark{Node: node, Image: logo}, 100, 150, gray, 2)).
+			To(Equal(Watermark{Node: node, Image: logo}.DrawWithBorder(100, 150, gray, 2)))
+	})
+})